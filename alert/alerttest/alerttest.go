@@ -0,0 +1,169 @@
+// Package alerttest provides utilities for testing code paths that raise
+// alerts via alert.LogHandler.
+//
+//	func TestCharge(t *testing.T) {
+//		logger, rec := alerttest.NewLogger(t)
+//		svc := payments.New(logger)
+//
+//		svc.Charge(ctx, invalidCard)
+//
+//		a := alerttest.RequireAlert(t, rec, "charge failed")
+//		if v, _ := a.Attr("payment.provider"); v.String() != "stripe" {
+//			t.Errorf("unexpected provider %v", v)
+//		}
+//	}
+package alerttest
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+// Alert is an alert captured by Recorder.
+type Alert struct {
+	Record slog.Record
+	Err    error
+}
+
+// Attrs returns the attrs of the alert record, including logger.With(...)
+// attrs, alert.With metadata and the "stack" attr.
+func (a Alert) Attrs() []slog.Attr {
+	var attrs []slog.Attr
+	a.Record.Attrs(func(attr slog.Attr) bool {
+		attrs = append(attrs, attr)
+		return true
+	})
+	return attrs
+}
+
+// Attr returns the value of the last attr with the given key. Attrs in
+// groups are addressed with dot-separated paths, e.g. "request.id".
+func (a Alert) Attr(key string) (slog.Value, bool) {
+	return lookup(a.Attrs(), strings.Split(key, "."))
+}
+
+func lookup(attrs []slog.Attr, path []string) (v slog.Value, ok bool) {
+	for _, attr := range attrs {
+		if attr.Key != path[0] {
+			continue
+		}
+		value := attr.Value.Resolve()
+		if len(path) == 1 {
+			v, ok = value, true
+		} else if value.Kind() == slog.KindGroup {
+			if gv, gok := lookup(value.Group(), path[1:]); gok {
+				v, ok = gv, true
+			}
+		}
+	}
+	return v, ok
+}
+
+// Recorder is an alert sink capturing alerts in memory. It is safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+// Alert records the alert. It can be passed to alert.LogHandler directly.
+func (r *Recorder) Alert(ctx context.Context, record slog.Record, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, Alert{Record: record.Clone(), Err: err})
+}
+
+// Alerts returns a copy of the captured alerts, in order.
+func (r *Recorder) Alerts() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Alert(nil), r.alerts...)
+}
+
+// Len returns the number of captured alerts.
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.alerts)
+}
+
+// Reset discards the captured alerts.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = nil
+}
+
+// Find returns the first alert whose error or message contains substr.
+func (r *Recorder) Find(substr string) (Alert, bool) {
+	for _, a := range r.Alerts() {
+		if strings.Contains(a.Record.Message, substr) || (a.Err != nil && strings.Contains(a.Err.Error(), substr)) {
+			return a, true
+		}
+	}
+	return Alert{}, false
+}
+
+// NewLogger returns a logger wired through alert.LogHandler to a new
+// Recorder. Log lines, at all levels, are written to t.Log.
+func NewLogger(t testing.TB, opts ...alert.HandlerOption) (*slog.Logger, *Recorder) {
+	t.Helper()
+	rec := &Recorder{}
+	handler := slog.NewTextHandler(&testWriter{t: t}, &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: alert.ReplaceAttr(nil, slog.String(slog.LevelKey, "ALERT")),
+	})
+	return slog.New(alert.LogHandler(handler, rec.Alert, opts...)), rec
+}
+
+// RequireAlert fails the test immediately unless an alert whose error or
+// message contains substr was captured, and returns the first such alert.
+func RequireAlert(t testing.TB, rec *Recorder, substr string) Alert {
+	t.Helper()
+	a, ok := rec.Find(substr)
+	if !ok {
+		t.Fatalf("alerttest: no alert matching %q, got %s", substr, summary(rec.Alerts()))
+	}
+	return a
+}
+
+// RequireNoAlerts fails the test immediately if any alert was captured.
+func RequireNoAlerts(t testing.TB, rec *Recorder) {
+	t.Helper()
+	if alerts := rec.Alerts(); len(alerts) > 0 {
+		t.Fatalf("alerttest: expected no alerts, got %s", summary(alerts))
+	}
+}
+
+func summary(alerts []Alert) string {
+	if len(alerts) == 0 {
+		return "none"
+	}
+	var b strings.Builder
+	for _, a := range alerts {
+		b.WriteString("\n\t")
+		b.WriteString(a.Record.Message)
+		if a.Err != nil {
+			b.WriteString(": ")
+			b.WriteString(a.Err.Error())
+		}
+	}
+	return b.String()
+}
+
+// testWriter writes each log line to t.Log.
+type testWriter struct {
+	t testing.TB
+}
+
+func (w *testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	w.t.Log(string(bytes.TrimSuffix(p, []byte("\n"))))
+	return len(p), nil
+}
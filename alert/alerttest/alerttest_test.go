@@ -0,0 +1,76 @@
+package alerttest
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+func TestNewLogger(t *testing.T) {
+	logger, rec := NewLogger(t)
+
+	logger.Error("plain error", slog.Any("error", errors.New("not found")))
+	RequireNoAlerts(t, rec)
+
+	logger.With(slog.Int("chainId", 137)).WithGroup("rpc").Error("call failed",
+		slog.String("method", "eth_call"),
+		slog.Any("error", alert.With(alert.Errorf("timeout"), "node", "alchemy")),
+	)
+
+	if rec.Len() != 1 {
+		t.Fatalf("expected 1 alert, got %d", rec.Len())
+	}
+	a := RequireAlert(t, rec, "timeout")
+	if a.Record.Message != "call failed" || a.Record.Level != alert.LevelAlert {
+		t.Errorf("unexpected record %+v", a.Record)
+	}
+	for key, want := range map[string]string{
+		"chainId":    "137",
+		"rpc.method": "eth_call",
+		"rpc.node":   "alchemy",
+	} {
+		if v, ok := a.Attr(key); !ok || v.String() != want {
+			t.Errorf("expected attr %s=%s, got %v", key, want, v)
+		}
+	}
+	if _, ok := a.Attr("stack"); !ok {
+		t.Error("expected stack attr")
+	}
+	if _, ok := a.Attr("rpc.missing"); ok {
+		t.Error("unexpected attr rpc.missing")
+	}
+
+	rec.Reset()
+	RequireNoAlerts(t, rec)
+}
+
+func TestRequireAlert_Fails(t *testing.T) {
+	logger, rec := NewLogger(t)
+	logger.Error("failed", slog.Any("error", alert.Errorf("timeout")))
+
+	ft := &fakeT{TB: t}
+	RequireAlert(ft, rec, "deadlock")
+	if !ft.failed {
+		t.Error("expected RequireAlert to fail")
+	}
+
+	ft = &fakeT{TB: t}
+	RequireNoAlerts(ft, rec)
+	if !ft.failed {
+		t.Error("expected RequireNoAlerts to fail")
+	}
+}
+
+// fakeT records Fatalf calls instead of failing the test.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeT) Fatalf(format string, args ...any) {
+	t.failed = true
+	t.TB.Log(fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,178 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink is a named alert callback, e.g. Sentry, PagerDuty or a webhook.
+type Sink struct {
+	Name  string
+	Alert func(ctx context.Context, record slog.Record, err error)
+
+	// Timeout of a single Alert call. The callback should respect ctx.
+	// Defaults to DispatcherOptions.Timeout.
+	Timeout time.Duration
+}
+
+// DispatcherOptions configures the async alert dispatcher, see NewDispatcher.
+type DispatcherOptions struct {
+	QueueSize int           // Max number of queued alerts. Defaults to 256.
+	Workers   int           // Number of worker goroutines. Defaults to 4.
+	Timeout   time.Duration // Default sink timeout. Defaults to 10s.
+	Logger    *slog.Logger  // Logs sink panics. Defaults to slog.Default().
+}
+
+// Dispatcher sends alerts to sinks from a pool of worker goroutines, so slow
+// sinks don't block the logging goroutine. Alerts are dropped when the queue
+// is full. The sinks of each alert run concurrently, so a slow sink doesn't
+// delay the others, but the worker is busy until all of them return.
+//
+//	dispatcher := alert.NewDispatcher(alert.DispatcherOptions{},
+//		alert.Sink{Name: "sentry", Alert: sentryAlert},
+//		alert.Sink{Name: "pagerduty", Alert: pagerdutyAlert, Timeout: 5 * time.Second},
+//	)
+//	defer dispatcher.Shutdown(context.Background())
+//
+//	slogHandler = alert.LogHandler(slogHandler, dispatcher.Alert)
+type Dispatcher struct {
+	sinks   []Sink
+	queue   chan dispatchJob
+	logger  *slog.Logger
+	dropped atomic.Uint64
+	panics  atomic.Uint64
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type dispatchJob struct {
+	ctx    context.Context
+	record slog.Record
+	err    error
+}
+
+// NewDispatcher starts the worker goroutines. Call Shutdown to stop them.
+func NewDispatcher(opts DispatcherOptions, sinks ...Sink) *Dispatcher {
+	timeout := cmp.Or(opts.Timeout, 10*time.Second)
+	sinks = slices.Clone(sinks)
+	for i, sink := range sinks {
+		if sink.Alert == nil {
+			panic(fmt.Sprintf("alert.NewDispatcher: sink %q: Alert is required", sink.Name))
+		}
+		sinks[i].Timeout = cmp.Or(sink.Timeout, timeout)
+	}
+
+	d := &Dispatcher{
+		sinks:  sinks,
+		queue:  make(chan dispatchJob, cmp.Or(opts.QueueSize, 256)),
+		logger: cmp.Or(opts.Logger, slog.Default()),
+	}
+	for range cmp.Or(opts.Workers, 4) {
+		d.workers.Add(1)
+		go d.work()
+	}
+
+	return d
+}
+
+// Alert queues the alert for all sinks. It never blocks.
+func (d *Dispatcher) Alert(ctx context.Context, record slog.Record, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		d.dropped.Add(1)
+		return
+	}
+
+	job := dispatchJob{
+		ctx:    context.WithoutCancel(ctx),
+		record: record.Clone(),
+		err:    err,
+	}
+	select {
+	case d.queue <- job:
+	default:
+		d.dropped.Add(1)
+	}
+}
+
+// Dropped returns the number of alerts dropped because the queue was full
+// or the dispatcher was shut down.
+func (d *Dispatcher) Dropped() uint64 {
+	return d.dropped.Load()
+}
+
+// Panics returns the number of sink calls that panicked.
+func (d *Dispatcher) Panics() uint64 {
+	return d.panics.Load()
+}
+
+func (d *Dispatcher) work() {
+	defer d.workers.Done()
+
+	for job := range d.queue {
+		if len(d.sinks) == 1 {
+			d.send(d.sinks[0], job)
+			continue
+		}
+
+		var wg sync.WaitGroup
+		for _, sink := range d.sinks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				d.send(sink, job)
+			}()
+		}
+		wg.Wait()
+	}
+}
+
+func (d *Dispatcher) send(sink Sink, job dispatchJob) {
+	ctx, cancel := context.WithTimeout(job.ctx, sink.Timeout)
+	defer cancel()
+
+	// A panicking sink must not take down the worker. The panic isn't logged
+	// as "panic" or "error" attr, so it can't trigger another alert.
+	defer func() {
+		if r := recover(); r != nil {
+			d.panics.Add(1)
+			d.logger.Error("alert: sink panicked", slog.String("sink", sink.Name), slog.String("recovered", fmt.Sprint(r)))
+		}
+	}()
+
+	sink.Alert(ctx, job.record.Clone(), job.err)
+}
+
+// Shutdown stops accepting new alerts and waits until the queued alerts
+// are sent, or until ctx is done.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("alert dispatcher shutdown: %w", ctx.Err())
+	}
+}
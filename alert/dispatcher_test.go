@@ -0,0 +1,138 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDispatcher_DoesNotBlockLogging(t *testing.T) {
+	unblock := make(chan struct{})
+	var rec alertRecorder
+
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 1, QueueSize: 1},
+		Sink{Name: "slow", Alert: func(ctx context.Context, record slog.Record, err error) {
+			<-unblock
+		}},
+		Sink{Name: "recorder", Alert: rec.Alert},
+	)
+
+	logger := slog.New(LogHandler(slog.NewTextHandler(io.Discard, nil), dispatcher.Alert))
+	done := make(chan struct{})
+	go func() {
+		for range 5 {
+			logger.Error("failed", slog.Any("error", Errorf("timeout")))
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected logging not to block on slow sink")
+	}
+
+	close(unblock)
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	// At most one alert in progress and one queued, the rest is dropped.
+	delivered, dropped := rec.Len(), int(dispatcher.Dropped())
+	if delivered+dropped != 5 || dropped < 3 {
+		t.Errorf("expected at least 3 of 5 alerts dropped, got delivered=%d dropped=%d", delivered, dropped)
+	}
+}
+
+func TestDispatcher_SinkTimeout(t *testing.T) {
+	var gotErr error
+	dispatcher := NewDispatcher(DispatcherOptions{Timeout: time.Hour},
+		Sink{Name: "slow", Timeout: 10 * time.Millisecond, Alert: func(ctx context.Context, record slog.Record, err error) {
+			<-ctx.Done()
+			gotErr = ctx.Err()
+		}},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dispatcher.Alert(ctx, slog.NewRecord(time.Now(), LevelAlert, "failed", 0), Errorf("timeout"))
+	// Cancelling the request context must not cancel the alert.
+	cancel()
+
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if !errors.Is(gotErr, context.DeadlineExceeded) {
+		t.Errorf("expected sink context deadline exceeded, got %v", gotErr)
+	}
+}
+
+func TestDispatcher_ShutdownTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	defer close(unblock)
+
+	dispatcher := NewDispatcher(DispatcherOptions{},
+		Sink{Name: "stuck", Alert: func(ctx context.Context, record slog.Record, err error) {
+			<-unblock
+		}},
+	)
+	dispatcher.Alert(context.Background(), slog.NewRecord(time.Now(), LevelAlert, "failed", 0), Errorf("timeout"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := dispatcher.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected shutdown deadline exceeded, got %v", err)
+	}
+}
+
+func TestDispatcher_SinkPanic(t *testing.T) {
+	var logs strings.Builder
+	var rec alertRecorder
+	dispatcher := NewDispatcher(DispatcherOptions{Logger: slog.New(slog.NewTextHandler(&logs, nil))},
+		Sink{Name: "broken", Alert: func(ctx context.Context, record slog.Record, err error) {
+			panic("nil map")
+		}},
+		Sink{Name: "recorder", Alert: rec.Alert},
+	)
+	dispatcher.Alert(context.Background(), slog.NewRecord(time.Now(), LevelAlert, "failed", 0), Errorf("timeout"))
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+
+	if got := dispatcher.Panics(); got != 1 {
+		t.Errorf("expected 1 panic, got %d", got)
+	}
+	if rec.Len() != 1 {
+		t.Errorf("expected other sinks to receive the alert, got %d alerts", rec.Len())
+	}
+	if !strings.Contains(logs.String(), "sink=broken") || !strings.Contains(logs.String(), `recovered="nil map"`) {
+		t.Errorf("expected sink panic to be logged, got %q", logs.String())
+	}
+}
+
+func TestDispatcher_SinksRunConcurrently(t *testing.T) {
+	unblock := make(chan struct{})
+	delivered := make(chan struct{})
+	dispatcher := NewDispatcher(DispatcherOptions{Workers: 1},
+		Sink{Name: "slow", Alert: func(ctx context.Context, record slog.Record, err error) {
+			<-unblock
+		}},
+		Sink{Name: "fast", Alert: func(ctx context.Context, record slog.Record, err error) {
+			close(delivered)
+		}},
+	)
+	dispatcher.Alert(context.Background(), slog.NewRecord(time.Now(), LevelAlert, "failed", 0), Errorf("timeout"))
+
+	select {
+	case <-delivered:
+	case <-time.After(time.Second):
+		t.Error("expected slow sink not to delay the others")
+	}
+	close(unblock)
+	if err := dispatcher.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+}
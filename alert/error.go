@@ -1,39 +1,76 @@
 package alert
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"runtime"
+	"sync/atomic"
 )
 
+// DefaultStackDepth is the default max number of stack frames captured by
+// alert errors, see SetStackDepth.
+const DefaultStackDepth = 32
+
+var stackDepth atomic.Int32
+
+func init() {
+	stackDepth.Store(DefaultStackDepth)
+}
+
+// SetStackDepth sets the max number of stack frames captured by alert
+// errors. Use WithStackDepth to override it for a single error.
+func SetStackDepth(depth int) {
+	stackDepth.Store(int32(max(depth, 1)))
+}
+
 // Errorf creates a new error with a stack trace that triggers
 // an alert when logged via alert.LogHandler.
 func Errorf(format string, args ...any) error {
-	return newAlertError(1, fmt.Errorf(format, args...))
+	err := newAlertError(2, fmt.Errorf(format, args...))
+	err.format = format
+	return err
 }
 
 // Error wraps an existing error with alert semantics and captures
 // a stack trace at the call site.
-func Error(err error) error {
-	return newAlertError(1, err)
+//
+//	alert.Error(err, alert.Owner("payments"), alert.Tags("stripe"))
+func Error(err error, opts ...Option) error {
+	return newAlertError(2, err, opts...)
 }
 
 // ErrorSkip wraps an existing error with alert semantics and captures
-// stack frames while skipping additional caller frames.
+// stack frames while skipping additional caller frames. Like
+// runtime.Callers, skip 0 is ErrorSkip itself, so ErrorSkip(1, err) is
+// equivalent to Error(err), and ErrorSkip(2, err) starts the stack trace at
+// the caller of the function calling ErrorSkip.
 // Use this when creating helper wrappers in another package.
-func ErrorSkip(skip int, err error) error {
-	return newAlertError(1+skip, err)
+func ErrorSkip(skip int, err error, opts ...Option) error {
+	return newAlertError(1+skip, err, opts...)
 }
 
-func newAlertError(skip int, err error) error {
-	alertErr := &alertError{err: err}
-	runtime.Callers(1+skip, alertErr.frame.frames[:])
+func newAlertError(skip int, err error, opts ...Option) *alertError {
+	alertErr := &alertError{err: err, depth: int(stackDepth.Load()), severity: SeverityError}
+	for _, opt := range opts {
+		opt(alertErr)
+	}
+	frames := make([]uintptr, alertErr.depth)
+	n := runtime.Callers(1+skip, frames)
+	alertErr.frames = frames[:n]
 	return alertErr
 }
 
 // alertError triggers alerts when logged.
 type alertError struct {
-	err   error
-	frame struct{ frames [3]uintptr }
+	err    error
+	format string // Errorf format, used to group alerts by message template.
+	depth  int
+	frames []uintptr
+
+	severity Severity
+	owner    string
+	tags     []string
 }
 
 func (e *alertError) Error() string {
@@ -47,6 +84,71 @@ func (e *alertError) Unwrap() error {
 	return e.err
 }
 
-func (e alertError) StackFrames() []uintptr {
-	return e.frame.frames[:]
+func (e *alertError) StackFrames() []uintptr {
+	return e.frames
+}
+
+// StackTrace returns the resolved stack frames captured at the call site.
+func (e *alertError) StackTrace() []runtime.Frame {
+	var stack []runtime.Frame
+	frames := runtime.CallersFrames(e.frames)
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			stack = append(stack, frame)
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// StackTrace returns the resolved stack frames captured by the alert error
+// in err's tree, or nil if err is not an alert error.
+func StackTrace(err error) []runtime.Frame {
+	var ae *alertError
+	if errors.As(err, &ae) {
+		return ae.StackTrace()
+	}
+	return nil
+}
+
+func stackStrings(frames []runtime.Frame) []string {
+	if len(frames) == 0 {
+		return nil
+	}
+	stack := make([]string, len(frames))
+	for i, frame := range frames {
+		stack[i] = fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
+	}
+	return stack
+}
+
+// Format implements fmt.Formatter. The %+v verb prints the error message
+// followed by the stack trace, one frame per line:
+//
+//	timeout
+//	main.fetch
+//		/app/main.go:42
+//	main.main
+//		/app/main.go:12
+func (e *alertError) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			io.WriteString(s, e.Error())
+			for _, frame := range e.StackTrace() {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+			}
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(%s)", verb, e.Error())
+	}
 }
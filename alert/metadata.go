@@ -0,0 +1,85 @@
+package alert
+
+import (
+	"log/slog"
+	"slices"
+)
+
+// With attaches structured key/values to err. Args are interpreted as in
+// slog.Logger.Log: key/value pairs or slog.Attr values. The metadata
+// survives wrapping with fmt.Errorf("...: %w", err) and errors.Join, and is
+// merged into the alert callback record and the log line when the alert is
+// logged via alert.LogHandler.
+//
+//	return alert.With(err, "chainId", chainID, slog.String("contract", addr))
+//
+// It returns nil if err is nil.
+func With(err error, args ...any) error {
+	if err == nil {
+		return nil
+	}
+
+	var r slog.Record
+	r.Add(args...)
+	attrs := make([]slog.Attr, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, a)
+		return true
+	})
+
+	return &metadataError{err: err, attrs: attrs}
+}
+
+// metadataError carries alert metadata attached by With.
+type metadataError struct {
+	err   error
+	attrs []slog.Attr
+}
+
+func (e *metadataError) Error() string {
+	return e.err.Error()
+}
+
+func (e *metadataError) Unwrap() error {
+	return e.err
+}
+
+// Attrs returns the metadata attached by With anywhere in err's tree.
+// Inner attrs come first, so outer With calls take precedence in
+// handlers that keep the last value of duplicate keys.
+func Attrs(err error) []slog.Attr {
+	var attrs []slog.Attr
+	walk(err, func(err error) {
+		if me, ok := err.(*metadataError); ok {
+			attrs = slices.Concat(me.attrs, attrs)
+		}
+	})
+	return attrs
+}
+
+// walk calls fn for err and every error in its tree, depth-first.
+func walk(err error, fn func(error)) {
+	if err == nil {
+		return
+	}
+	fn(err)
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		walk(e.Unwrap(), fn)
+	case interface{ Unwrap() []error }:
+		for _, err := range e.Unwrap() {
+			walk(err, fn)
+		}
+	}
+}
+
+// withMetadata returns a copy of record with the metadata attached to err.
+func withMetadata(record slog.Record, err error) slog.Record {
+	attrs := Attrs(err)
+	if len(attrs) == 0 {
+		return record
+	}
+	record = record.Clone()
+	record.AddAttrs(attrs...)
+	return record
+}
@@ -0,0 +1,170 @@
+package alert
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/0xsequence/go-libs/config"
+)
+
+// RouterConfig can be used directly in toml config.
+//
+//	[alerts]
+//	  default = ["slack"]
+//
+//	  [[alerts.routes]]
+//	    severity = ["critical"]
+//	    env = ["prod"]
+//	    sinks = ["pagerduty", "slack"]
+//
+//	  [[alerts.routes]]
+//	    tags = ["payments"]
+//	    sinks = ["payments-webhook"]
+//
+//	  [[alerts.routes]]
+//	    severity = ["warning"]
+//	    mute = true
+type RouterConfig struct {
+	Routes  []Route  `toml:"routes"`
+	Default []string `toml:"default"` // Sinks for alerts not matching any route.
+}
+
+// Route sends alerts matching all of its non-empty criteria to Sinks.
+// Each criterion matches if any of its values match. A route must have
+// sinks, or set Mute to drop matching alerts.
+type Route struct {
+	Severity []Severity   `toml:"severity"`
+	Owner    []string     `toml:"owner"`
+	Tags     []string     `toml:"tags"`
+	Env      []config.Env `toml:"env"`
+	Service  []string     `toml:"service"`
+
+	Sinks []string `toml:"sinks"`
+	Mute  bool     `toml:"mute"` // Drops matching alerts. Mutually exclusive with Sinks.
+
+	// Continue evaluating the following routes after this route matched.
+	// By default, the first matching route wins.
+	Continue bool `toml:"continue"`
+}
+
+// RouterOptions describe the running service, matched against
+// Route.Env and Route.Service.
+type RouterOptions struct {
+	Env     config.Env
+	Service string
+}
+
+// Router dispatches alerts to sinks based on the alert severity, owner and
+// tags (see Critical, Warning, Owner and Tags), and the environment and
+// service the router runs in.
+//
+//	router, err := alert.NewRouter(cfg.Alerts, alert.RouterOptions{Env: cfg.Env, Service: "api"},
+//		alert.Sink{Name: "slack", Alert: slackAlert},
+//		alert.Sink{Name: "pagerduty", Alert: pagerdutyAlert},
+//	)
+//
+//	slogHandler = alert.LogHandler(slogHandler, router.Alert)
+type Router struct {
+	routes   []Route
+	defaults []Sink
+	sinks    map[string]Sink
+	opts     RouterOptions
+}
+
+// NewRouter validates that all sinks referenced in cfg are provided.
+func NewRouter(cfg RouterConfig, opts RouterOptions, sinks ...Sink) (*Router, error) {
+	r := &Router{
+		routes: cfg.Routes,
+		sinks:  map[string]Sink{},
+		opts:   opts,
+	}
+	for _, sink := range sinks {
+		if sink.Alert == nil {
+			return nil, fmt.Errorf("sink %q: Alert is required", sink.Name)
+		}
+		r.sinks[sink.Name] = sink
+	}
+
+	for i, route := range cfg.Routes {
+		switch {
+		case route.Mute && len(route.Sinks) > 0:
+			return nil, fmt.Errorf("routes[%d]: mute and sinks are mutually exclusive", i)
+		case !route.Mute && len(route.Sinks) == 0:
+			return nil, fmt.Errorf("routes[%d]: no sinks, set mute = true to drop matching alerts", i)
+		}
+		for _, name := range route.Sinks {
+			if _, ok := r.sinks[name]; !ok {
+				return nil, fmt.Errorf("routes[%d]: unknown sink %q", i, name)
+			}
+		}
+	}
+	for _, name := range cfg.Default {
+		sink, ok := r.sinks[name]
+		if !ok {
+			return nil, fmt.Errorf("default: unknown sink %q", name)
+		}
+		r.defaults = append(r.defaults, sink)
+	}
+
+	return r, nil
+}
+
+// Alert sends the alert to the sinks of matching routes, or to the default
+// sinks if no route matches. Each sink is called at most once per alert.
+func (r *Router) Alert(ctx context.Context, record slog.Record, err error) {
+	for _, sink := range r.Match(err) {
+		sink.Alert(ctx, record.Clone(), err)
+	}
+}
+
+// Match returns the sinks the alert error would be dispatched to.
+func (r *Router) Match(err error) []Sink {
+	var names []string
+	var matched bool
+	for _, route := range r.routes {
+		if !r.matches(route, err) {
+			continue
+		}
+		matched = true
+		for _, name := range route.Sinks {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+		if !route.Continue {
+			break
+		}
+	}
+	if !matched {
+		return r.defaults
+	}
+
+	sinks := make([]Sink, len(names))
+	for i, name := range names {
+		sinks[i] = r.sinks[name]
+	}
+	return sinks
+}
+
+func (r *Router) matches(route Route, err error) bool {
+	if len(route.Severity) > 0 && !slices.Contains(route.Severity, SeverityOf(err)) {
+		return false
+	}
+	if len(route.Owner) > 0 && !slices.Contains(route.Owner, OwnerOf(err)) {
+		return false
+	}
+	if len(route.Tags) > 0 && !slices.ContainsFunc(TagsOf(err), func(tag string) bool {
+		return slices.Contains(route.Tags, tag)
+	}) {
+		return false
+	}
+	if len(route.Env) > 0 && !slices.Contains(route.Env, r.opts.Env) {
+		return false
+	}
+	if len(route.Service) > 0 && !slices.Contains(route.Service, r.opts.Service) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/0xsequence/go-libs/config"
+)
+
+func TestRouter(t *testing.T) {
+	var cfg struct {
+		Alerts RouterConfig `toml:"alerts"`
+	}
+	_, err := toml.Decode(`
+		[alerts]
+		  default = ["slack"]
+
+		  [[alerts.routes]]
+		    severity = ["critical"]
+		    env = ["prod"]
+		    sinks = ["pagerduty", "slack"]
+		    continue = true
+
+		  [[alerts.routes]]
+		    tags = ["payments"]
+		    sinks = ["payments", "slack"]
+
+		  [[alerts.routes]]
+		    severity = ["warning"]
+		    mute = true
+	`, &cfg)
+	if err != nil {
+		t.Fatalf("decode config: %v", err)
+	}
+
+	got := map[string]int{}
+	sink := func(name string) Sink {
+		return Sink{Name: name, Alert: func(ctx context.Context, record slog.Record, err error) {
+			got[name]++
+		}}
+	}
+
+	router, err := NewRouter(cfg.Alerts, RouterOptions{Env: config.EnvProd, Service: "api"},
+		sink("slack"), sink("pagerduty"), sink("payments"),
+	)
+	if err != nil {
+		t.Fatalf("unexpected router error: %v", err)
+	}
+
+	tt := []struct {
+		name string
+		err  error
+		want map[string]int
+	}{
+		{name: "default", err: Errorf("timeout"), want: map[string]int{"slack": 1}},
+		{name: "critical", err: Critical(errors.New("down")), want: map[string]int{"pagerduty": 1, "slack": 1}},
+		{name: "critical payments", err: Critical(errors.New("down"), Tags("payments")), want: map[string]int{"pagerduty": 1, "slack": 1, "payments": 1}},
+		{name: "warning is muted", err: Warning(errors.New("slow")), want: map[string]int{}},
+	}
+
+	for _, tt := range tt {
+		t.Run(tt.name, func(t *testing.T) {
+			clear(got)
+			router.Alert(context.Background(), slog.NewRecord(time.Now(), LevelAlert, "failed", 0), tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected sinks %v, got %v", tt.want, got)
+			}
+			for name, n := range tt.want {
+				if got[name] != n {
+					t.Errorf("expected sinks %v, got %v", tt.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRouter_UnknownSink(t *testing.T) {
+	_, err := NewRouter(RouterConfig{Routes: []Route{{Sinks: []string{"missing"}}}}, RouterOptions{})
+	if err == nil {
+		t.Fatal("expected error for unknown sink")
+	}
+}
+
+func TestRouter_RouteWithoutSinks(t *testing.T) {
+	slack := Sink{Name: "slack", Alert: func(ctx context.Context, record slog.Record, err error) {}}
+	for _, route := range []Route{
+		{Severity: []Severity{SeverityWarning}},
+		{Severity: []Severity{SeverityWarning}, Sinks: []string{"slack"}, Mute: true},
+	} {
+		if _, err := NewRouter(RouterConfig{Routes: []Route{route}}, RouterOptions{}, slack); err == nil {
+			t.Errorf("expected error for route %+v", route)
+		}
+	}
+}
+
+func TestSeverityOptions(t *testing.T) {
+	err := Critical(errors.New("down"), Owner("infra"), Tags("db", "primary"))
+	if SeverityOf(err) != SeverityCritical {
+		t.Errorf("expected critical severity, got %v", SeverityOf(err))
+	}
+	if OwnerOf(err) != "infra" {
+		t.Errorf("expected owner infra, got %q", OwnerOf(err))
+	}
+	if tags := TagsOf(err); len(tags) != 2 || tags[0] != "db" || tags[1] != "primary" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+	if SeverityOf(Errorf("timeout")) != SeverityError {
+		t.Error("expected Errorf to have error severity")
+	}
+
+	if SeverityOf(errors.New("plain")) != SeverityError {
+		t.Error("expected plain errors to have error severity")
+	}
+	if !(SeverityWarning < SeverityError && SeverityError < SeverityCritical) {
+		t.Error("expected severities ordered from low to high")
+	}
+
+	var s Severity
+	if err := s.UnmarshalText([]byte("fatal")); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+	if err := s.UnmarshalText(nil); err != nil || s != SeverityError {
+		t.Errorf("expected empty severity to be error, got %v", s)
+	}
+
+	var unset Severity
+	if text, _ := unset.MarshalText(); len(text) != 0 {
+		t.Errorf("expected unset severity to marshal to an empty string, got %q", text)
+	}
+	if SeverityOf(Error(errors.New("unset"), WithSeverity(unset))) != SeverityError {
+		t.Error("expected unset severity to be error")
+	}
+	for _, severity := range []Severity{SeverityWarning, SeverityError, SeverityCritical} {
+		text, _ := severity.MarshalText()
+		if err := s.UnmarshalText(text); err != nil || s != severity {
+			t.Errorf("expected %v to round trip, got %v, %v", severity, s, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package alert
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Severity of an alert, used to route alerts to different sinks.
+// Severities are ordered from low to high, e.g. s >= SeverityError.
+// The zero value is unset and treated as SeverityError, like an empty
+// string in UnmarshalText.
+type Severity uint8
+
+const (
+	SeverityWarning  Severity = iota + 1 // Needs attention, but not urgently.
+	SeverityError                        // Default severity of Errorf and Error.
+	SeverityCritical                     // Needs immediate attention, e.g. paging on-call.
+)
+
+var severities = []string{
+	"",         // 0, unset
+	"warning",  // 1
+	"error",    // 2
+	"critical", // 3
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	enum := string(text)
+
+	// on empty string fallback to "error"
+	if enum == "" {
+		*s = SeverityError
+		return nil
+	}
+
+	for i, name := range severities[1:] {
+		if enum == name {
+			*s = Severity(i + 1)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown severity=(%s), supported=(%s)", text, strings.Join(severities[1:], ","))
+}
+
+func (s Severity) String() string {
+	if int(s) >= len(severities) {
+		return fmt.Sprintf("Severity(%d)", s)
+	}
+
+	return severities[s]
+}
+
+// Option sets alert details on errors created by Error, Critical and Warning.
+type Option func(*alertError)
+
+// WithSeverity sets the alert severity.
+func WithSeverity(severity Severity) Option {
+	return func(e *alertError) {
+		e.severity = severity
+	}
+}
+
+// Owner sets the team or person owning the alert.
+func Owner(owner string) Option {
+	return func(e *alertError) {
+		e.owner = owner
+	}
+}
+
+// Tags adds tags to the alert.
+func Tags(tags ...string) Option {
+	return func(e *alertError) {
+		e.tags = append(e.tags, tags...)
+	}
+}
+
+// Critical wraps an existing error as an alert with SeverityCritical.
+func Critical(err error, opts ...Option) error {
+	return newAlertError(2, err, append([]Option{WithSeverity(SeverityCritical)}, opts...)...)
+}
+
+// Warning wraps an existing error as an alert with SeverityWarning.
+func Warning(err error, opts ...Option) error {
+	return newAlertError(2, err, append([]Option{WithSeverity(SeverityWarning)}, opts...)...)
+}
+
+// SeverityOf returns the severity of the alert error in err's tree.
+// It returns SeverityError if err is not an alert error.
+func SeverityOf(err error) Severity {
+	var ae *alertError
+	if errors.As(err, &ae) {
+		return cmp.Or(ae.severity, SeverityError)
+	}
+	return SeverityError
+}
+
+// OwnerOf returns the owner of the alert error in err's tree.
+func OwnerOf(err error) string {
+	var ae *alertError
+	if errors.As(err, &ae) {
+		return ae.owner
+	}
+	return ""
+}
+
+// TagsOf returns the tags of the alert error in err's tree.
+func TagsOf(err error) []string {
+	var ae *alertError
+	if errors.As(err, &ae) {
+		return ae.tags
+	}
+	return nil
+}
+
+// WithStackDepth sets the max number of stack frames captured by the error.
+func WithStackDepth(depth int) Option {
+	return func(e *alertError) {
+		e.depth = max(depth, 1)
+	}
+}
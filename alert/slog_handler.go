@@ -3,7 +3,11 @@ package alert
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"runtime"
+	"slices"
+	"strings"
 )
 
 // LevelAlert is a custom slog level (16) for alert errors. It is greater than
@@ -33,13 +37,17 @@ func ReplaceAttr(next func(groups []string, a slog.Attr) slog.Attr, alertAttr sl
 }
 
 // LogHandler wraps a slog.Handler and invokes the alert callback when a log
-// record contains an error from Errorf (private alertError type). When an alert
+// record contains an error from Errorf (private alertError type). Any
+// error-valued attr is inspected, including attrs inside groups and errors
+// wrapped with fmt.Errorf("%w") or errors.Join. When an alert
 // is triggered, the record's level is upgraded to LevelAlert before passing to
 // the next handler, so GCP receives severity="ALERT" and level filters treat it
 // as >= ERROR. Use ReplaceAttr to map LevelAlert to sink-specific attrs.
 //
 // The callback receives the matched error and a record that includes call-site
-// attrs plus logger.With(...) / logger.WithGroup(...) context.
+// attrs plus logger.With(...) / logger.WithGroup(...) context, metadata attached
+// to the error by alert.With, and a top-level "stack" attr with the resolved
+// stack trace of the alert error. The metadata is added to the log line too.
 //
 // IMPORTANT: treat alertFn as a side-effect hook only (Sentry, paging,
 // webhooks, metrics). Do not log with slog from inside alertFn, especially
@@ -59,19 +67,50 @@ func ReplaceAttr(next func(groups []string, a slog.Attr) slog.Attr, alertAttr sl
 //	slogHandler = alert.LogHandler(slogHandler, func(ctx context.Context, record slog.Record, err error) {
 //	    sentry.CaptureException(err)
 //	})
-func LogHandler(handler slog.Handler, alertFn func(ctx context.Context, record slog.Record, err error)) slog.Handler {
+func LogHandler(handler slog.Handler, alertFn func(ctx context.Context, record slog.Record, err error), opts ...HandlerOption) slog.Handler {
 	if alertFn == nil {
 		panic("alert.LogHandler: alertFn is required")
 	}
-	return &alertHandler{
+	h := &alertHandler{
 		handler: handler,
 		alertFn: alertFn,
 	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	return h
+}
+
+// HandlerOption configures LogHandler.
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	panics    bool
+	errorKeys []string
+}
+
+// DetectPanics treats recovered panics as alerts, even if they are not alert
+// errors. A record is considered a recovered panic if it has an attr with
+// the "panic" key, or an error message attr with a value starting with
+// "panic: ", as logged by httplog.RequestLogger with RecoverPanics enabled.
+//
+// Error message keys are "error" and "error.message", as used by the
+// httplog schemas (GCP, ECS, OTEL and Datadog), and the given errorKeys.
+// Keys match at the top level or in groups, e.g. "error.message" matches
+// the "message" attr in the "error" group. slog.LogValuer values are
+// resolved. The alert error passed to alertFn is tagged with "panic", with
+// the stack trace of the logging call.
+func DetectPanics(errorKeys ...string) HandlerOption {
+	return func(o *handlerOptions) {
+		o.panics = true
+		o.errorKeys = append([]string{"error", "error.message"}, errorKeys...)
+	}
 }
 
 type alertHandler struct {
 	handler    slog.Handler
 	alertFn    func(ctx context.Context, record slog.Record, err error)
+	opts       handlerOptions
 	parent     *alertHandler
 	localAttrs []slog.Attr
 	localGroup string
@@ -87,24 +126,12 @@ func (h *alertHandler) Enabled(ctx context.Context, level slog.Level) bool {
 type callbackContextKey struct{}
 
 func (h *alertHandler) Handle(ctx context.Context, record slog.Record) error {
-	var alertErr error
-	record.Attrs(func(a slog.Attr) bool {
-		if a.Key != "error" || a.Value.Kind() != slog.KindAny {
-			return true
-		}
-		e, ok := a.Value.Any().(error)
-		if !ok || e == nil {
-			return true
-		}
-
-		var ae *alertError
-		if errors.As(e, &ae) {
-			alertErr = e
-			return false
-		}
-		return true
-	})
+	alertErr := findAlertError(record)
+	if alertErr == nil && h.opts.panics {
+		alertErr = findPanic(record, h.opts.errorKeys)
+	}
 	if alertErr != nil {
+		record = withMetadata(record, alertErr)
 		record.Level = LevelAlert
 	}
 	if !h.handler.Enabled(ctx, record.Level) {
@@ -118,6 +145,9 @@ func (h *alertHandler) Handle(ctx context.Context, record slog.Record) error {
 		}
 		callbackRecord := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
 		callbackRecord.AddAttrs(h.buildAttrs(record)...)
+		if stack := stackStrings(StackTrace(alertErr)); len(stack) > 0 {
+			callbackRecord.AddAttrs(slog.Any("stack", stack))
+		}
 		callbackCtx := context.WithValue(ctx, callbackContextKey{}, true)
 		h.alertFn(callbackCtx, callbackRecord, alertErr)
 		return h.handler.Handle(ctx, record) //nolint:wrapcheck
@@ -130,6 +160,7 @@ func (h *alertHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &alertHandler{
 		handler:    h.handler.WithAttrs(attrs),
 		alertFn:    h.alertFn,
+		opts:       h.opts,
 		parent:     h,
 		localAttrs: localAttrs,
 	}
@@ -139,11 +170,108 @@ func (h *alertHandler) WithGroup(name string) slog.Handler {
 	return &alertHandler{
 		handler:    h.handler.WithGroup(name),
 		alertFn:    h.alertFn,
+		opts:       h.opts,
 		parent:     h,
 		localGroup: name,
 	}
 }
 
+// findAlertError returns the first error-valued attr, under any key and in
+// any group, which has an alert error in its tree (including errors.Join).
+func findAlertError(record slog.Record) error {
+	var alertErr error
+	record.Attrs(func(a slog.Attr) bool {
+		alertErr = findAlertErrorAttr(a)
+		return alertErr == nil
+	})
+	return alertErr
+}
+
+func findAlertErrorAttr(a slog.Attr) error {
+	switch a.Value.Kind() {
+	case slog.KindAny:
+		e, ok := a.Value.Any().(error)
+		if !ok || e == nil {
+			return nil
+		}
+		var ae *alertError
+		if errors.As(e, &ae) {
+			return e
+		}
+	case slog.KindGroup:
+		for _, a := range a.Value.Group() {
+			if err := findAlertErrorAttr(a); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findPanic returns an alert error for a recovered panic logged in record,
+// under any group. Its stack trace starts at the logging call, which runs
+// in the deferred recover func, so the panicking frames are included.
+func findPanic(record slog.Record, errorKeys []string) error {
+	var alertErr *alertError
+	record.Attrs(func(a slog.Attr) bool {
+		alertErr = findPanicAttr(a, "", errorKeys)
+		return alertErr == nil
+	})
+	if alertErr == nil {
+		return nil
+	}
+	alertErr.severity = SeverityError
+	alertErr.tags = []string{"panic"}
+	alertErr.depth = int(stackDepth.Load())
+	alertErr.frames = callerFrames(record.PC, alertErr.depth)
+	return alertErr
+}
+
+// findPanicAttr finds a panic in a, where group is the dot-separated path
+// of the groups containing a.
+func findPanicAttr(a slog.Attr, group string, errorKeys []string) *alertError {
+	a.Value = a.Value.Resolve()
+	key := a.Key
+	if group != "" && key != "" {
+		key = group + "." + key
+	} else if key == "" {
+		key = group // Inlined group.
+	}
+
+	switch {
+	case a.Value.Kind() == slog.KindGroup:
+		for _, a := range a.Value.Group() {
+			if alertErr := findPanicAttr(a, key, errorKeys); alertErr != nil {
+				return alertErr
+			}
+		}
+	case a.Key == "panic":
+		if e, ok := a.Value.Any().(error); ok {
+			return &alertError{err: fmt.Errorf("panic: %w", e)}
+		}
+		return &alertError{err: fmt.Errorf("panic: %v", a.Value)}
+	case (slices.Contains(errorKeys, a.Key) || slices.Contains(errorKeys, key)) && strings.HasPrefix(a.Value.String(), "panic: "):
+		if e, ok := a.Value.Any().(error); ok {
+			return &alertError{err: e}
+		}
+		return &alertError{err: errors.New(a.Value.String())}
+	}
+	return nil
+}
+
+// callerFrames returns up to depth stack frames of the current goroutine,
+// starting at pc, the logging call site of a record. If pc isn't found, e.g.
+// because it's zero, the frames start at the caller of callerFrames.
+func callerFrames(pc uintptr, depth int) []uintptr {
+	// Leave room for the slog and handler frames above the call site.
+	frames := make([]uintptr, depth+64)
+	frames = frames[:runtime.Callers(2, frames)]
+	if i := slices.Index(frames, pc); i >= 0 && pc != 0 {
+		frames = frames[i:]
+	}
+	return frames[:min(len(frames), depth)]
+}
+
 // buildAttrs flattens logger.With(...) attrs from the parent chain and
 // appends the current record attrs while preserving WithGroup nesting order.
 func (h *alertHandler) buildAttrs(record slog.Record) []slog.Attr {
@@ -274,3 +274,222 @@ func TestLogHandler_PanicsOnNilCallback(t *testing.T) {
 	}()
 	_ = LogHandler(slog.NewTextHandler(io.Discard, nil), nil)
 }
+
+func TestStackTrace(t *testing.T) {
+	err := Errorf("timeout")
+
+	stack := StackTrace(err)
+	if len(stack) < 2 {
+		t.Fatalf("expected multiple resolved frames, got %+v", stack)
+	}
+	if !strings.Contains(stack[0].Function, "TestStackTrace") {
+		t.Errorf("expected first frame to be the caller, got %q", stack[0].Function)
+	}
+
+	if got := fmt.Sprintf("%v", err); got != "timeout" {
+		t.Errorf("expected %%v to print message only, got %q", got)
+	}
+	if got := fmt.Sprintf("%+v", err); !strings.HasPrefix(got, "timeout\n") || !strings.Contains(got, "slog_handler_test.go:") {
+		t.Errorf("expected %%+v to print stack trace, got %q", got)
+	}
+
+	if got := len(StackTrace(Error(err, WithStackDepth(1)))); got != 1 {
+		t.Errorf("expected 1 frame with WithStackDepth(1), got %d", got)
+	}
+	if StackTrace(errors.New("plain")) != nil {
+		t.Error("expected no stack trace for plain error")
+	}
+
+	if got := fmt.Sprintf("%d", err); got != "%!d(timeout)" {
+		t.Errorf("expected %%d to print bad verb with message, got %q", got)
+	}
+}
+
+func TestErrorSkip(t *testing.T) {
+	stack := StackTrace(ErrorSkip(0, errors.New("timeout")))
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, ".ErrorSkip") {
+		t.Fatalf("expected ErrorSkip(0) to start at ErrorSkip, got %+v", stack)
+	}
+
+	stack = StackTrace(ErrorSkip(1, errors.New("timeout")))
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestErrorSkip") {
+		t.Fatalf("expected ErrorSkip(1) to start at the caller, got %+v", stack)
+	}
+
+	helper := func() error { return ErrorSkip(2, errors.New("timeout")) }
+	stack = StackTrace(helper())
+	if len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestErrorSkip") {
+		t.Fatalf("expected ErrorSkip(2) to skip the helper, got %+v", stack)
+	}
+}
+
+func TestLogHandler_CallbackHasStackAttr(t *testing.T) {
+	var stack []string
+	handler := LogHandler(slog.NewTextHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+		record.Attrs(func(a slog.Attr) bool {
+			if a.Key == "stack" {
+				stack, _ = a.Value.Any().([]string)
+			}
+			return true
+		})
+	})
+	slog.New(handler).Error("failed", slog.Any("error", Errorf("timeout")))
+
+	if len(stack) == 0 || !strings.Contains(stack[0], "slog_handler_test.go:") {
+		t.Fatalf("expected stack attr with test caller, got %v", stack)
+	}
+}
+
+func TestLogHandler_MergesErrorMetadata(t *testing.T) {
+	var gotAttrs []slog.Attr
+	var logBuf strings.Builder
+	handler := LogHandler(slog.NewTextHandler(&logBuf, nil), func(ctx context.Context, record slog.Record, err error) {
+		record.Attrs(func(a slog.Attr) bool {
+			gotAttrs = append(gotAttrs, a)
+			return true
+		})
+	})
+
+	err := With(Errorf("timeout"), "chainId", 137)
+	err = fmt.Errorf("fetch block: %w", With(err, slog.String("contract", "0xabc")))
+	err = errors.Join(errors.New("other"), err)
+	slog.New(handler).Error("failed", slog.Any("error", err))
+
+	want := map[string]string{"chainId": "137", "contract": "0xabc"}
+	for key, value := range want {
+		var found bool
+		for _, a := range gotAttrs {
+			if a.Key == key && a.Value.String() == value {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected callback attr %s=%s, got %+v", key, value, gotAttrs)
+		}
+		if !strings.Contains(logBuf.String(), key+"="+value) {
+			t.Errorf("expected log line to contain %s=%s, got %q", key, value, logBuf.String())
+		}
+	}
+}
+
+func TestWith(t *testing.T) {
+	if With(nil, "key", "value") != nil {
+		t.Error("expected With(nil) to return nil")
+	}
+
+	base := errors.New("base")
+	err := With(base, "a", 1)
+	if !errors.Is(err, base) || err.Error() != "base" {
+		t.Errorf("expected With to wrap err transparently, got %v", err)
+	}
+
+	attrs := Attrs(fmt.Errorf("outer: %w", With(err, "b", 2)))
+	if len(attrs) != 2 || attrs[0].Key != "a" || attrs[1].Key != "b" {
+		t.Errorf("expected inner attrs first, got %+v", attrs)
+	}
+}
+
+func TestLogHandler_DetectsAlertInAnyAttr(t *testing.T) {
+	tt := []struct {
+		name string
+		attr slog.Attr
+	}{
+		{name: "custom key", attr: slog.Any("cause", Errorf("timeout"))},
+		{name: "group", attr: slog.Group("rpc", slog.Any("err", Errorf("timeout")))},
+		{name: "joined", attr: slog.Any("error", errors.Join(errors.New("plain"), Errorf("timeout")))},
+		{name: "wrapped", attr: slog.Any("error", fmt.Errorf("fetch: %w", Errorf("timeout")))},
+	}
+	for _, tt := range tt {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			handler := LogHandler(slog.NewTextHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+				called = true
+			})
+			slog.New(handler).Info("failed", tt.attr)
+			if !called {
+				t.Error("expected alert callback")
+			}
+		})
+	}
+}
+
+func TestLogHandler_DetectPanics(t *testing.T) {
+	tt := []struct {
+		name  string
+		attrs []any
+		want  bool
+	}{
+		{name: "panic key", attrs: []any{slog.Any("panic", "nil map")}, want: true},
+		{name: "httplog panic", attrs: []any{slog.String("error", "panic: nil map")}, want: true},
+		{name: "plain error", attrs: []any{slog.String("error", "not found")}, want: false},
+		{name: "grouped panic", attrs: []any{slog.Group("request", slog.Any("panic", "nil map"))}, want: true},
+		{name: "log valuer panic", attrs: []any{slog.Any("request", panicValuer{})}, want: true},
+		{name: "ecs panic", attrs: []any{slog.String("error.message", "panic: nil map")}, want: true},
+		{name: "grouped ecs panic", attrs: []any{slog.Group("error", slog.String("message", "panic: nil map"))}, want: true},
+		{name: "other message", attrs: []any{slog.Group("http", slog.String("message", "panic: nil map"))}, want: false},
+	}
+	for _, tt := range tt {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotErr error
+			alertFn := func(ctx context.Context, record slog.Record, err error) {
+				gotErr = err
+			}
+
+			slog.New(LogHandler(slog.NewTextHandler(io.Discard, nil), alertFn)).Error("failed", tt.attrs...)
+			if gotErr != nil {
+				t.Fatal("expected no alert without DetectPanics")
+			}
+
+			slog.New(LogHandler(slog.NewTextHandler(io.Discard, nil), alertFn, DetectPanics())).Error("failed", tt.attrs...)
+			if (gotErr != nil) != tt.want {
+				t.Fatalf("expected alert=%v, got %v", tt.want, gotErr)
+			}
+			if tt.want {
+				if !strings.HasPrefix(gotErr.Error(), "panic: nil map") {
+					t.Errorf("unexpected panic error %q", gotErr)
+				}
+				if tags := TagsOf(gotErr); len(tags) != 1 || tags[0] != "panic" {
+					t.Errorf("expected panic tag, got %v", tags)
+				}
+				if stack := StackTrace(gotErr); len(stack) == 0 || !strings.HasSuffix(stack[0].Function, "TestLogHandler_DetectPanics.func1") {
+					t.Errorf("expected stack trace starting at the logging call, got %v", stack)
+				}
+			}
+		})
+	}
+}
+
+func TestLogHandler_DetectPanicsStack(t *testing.T) {
+	var gotErr error
+	logger := slog.New(LogHandler(slog.NewTextHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+		gotErr = err
+	}, DetectPanics()))
+
+	func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				logger.Error("recovered", slog.Any("panic", rec))
+			}
+		}()
+		panicking()
+	}()
+
+	var found bool
+	for _, frame := range StackTrace(gotErr) {
+		found = found || strings.HasSuffix(frame.Function, ".panicking")
+	}
+	if !found {
+		t.Errorf("expected panicking frame in stack trace, got %v", StackTrace(gotErr))
+	}
+}
+
+//go:noinline
+func panicking() {
+	panic("nil map")
+}
+
+type panicValuer struct{}
+
+func (panicValuer) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("error", "panic: nil map"))
+}
@@ -0,0 +1,218 @@
+package alert
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ThrottleOptions configures alert deduplication, see NewThrottler.
+type ThrottleOptions struct {
+	// Cooldown is the window in which at most Burst alerts with the same
+	// fingerprint are passed through. Defaults to 1 minute.
+	Cooldown time.Duration
+
+	// Burst is the number of alerts with the same fingerprint passed through
+	// per Cooldown window. Defaults to 1.
+	Burst int
+
+	// SummaryInterval is how often a summary alert is sent for fingerprints
+	// with suppressed occurrences. Defaults to Cooldown.
+	SummaryInterval time.Duration
+
+	// Attrs are record attr keys included in the fingerprint, e.g. "chainId",
+	// so the same error on different chains alerts separately.
+	Attrs []string
+}
+
+// Throttler deduplicates alerts by fingerprint (see Fingerprint) and limits
+// how often alerts with the same fingerprint reach the alert callback.
+// Suppressed occurrences are reported periodically in a summary alert
+// with a "suppressed" attr.
+//
+//	throttler := alert.NewThrottler(sentryAlert, alert.ThrottleOptions{Cooldown: 5 * time.Minute})
+//	defer throttler.Close()
+//
+//	slogHandler = alert.LogHandler(slogHandler, throttler.Alert)
+type Throttler struct {
+	alertFn func(ctx context.Context, record slog.Record, err error)
+	opts    ThrottleOptions
+	now     func() time.Time
+
+	mu      sync.Mutex
+	windows map[string]*throttleWindow
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type throttleWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+	record     slog.Record // Last suppressed record.
+	err        error       // Last suppressed error.
+}
+
+// NewThrottler wraps alertFn with deduplication and starts a goroutine
+// sending summary alerts. Call Close to stop it.
+func NewThrottler(alertFn func(ctx context.Context, record slog.Record, err error), opts ThrottleOptions) *Throttler {
+	if alertFn == nil {
+		panic("alert.NewThrottler: alertFn is required")
+	}
+	opts.Cooldown = cmp.Or(opts.Cooldown, time.Minute)
+	opts.Burst = cmp.Or(opts.Burst, 1)
+	opts.SummaryInterval = cmp.Or(opts.SummaryInterval, opts.Cooldown)
+
+	t := &Throttler{
+		alertFn: alertFn,
+		opts:    opts,
+		now:     time.Now,
+		windows: map[string]*throttleWindow{},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go t.run()
+
+	return t
+}
+
+// Alert passes the alert to the wrapped alertFn, unless the fingerprint
+// exceeded its burst in the current cooldown window.
+func (t *Throttler) Alert(ctx context.Context, record slog.Record, err error) {
+	fingerprint := Fingerprint(err, record, t.opts.Attrs...)
+	now := t.now()
+
+	t.mu.Lock()
+	w, ok := t.windows[fingerprint]
+	if !ok || now.Sub(w.start) >= t.opts.Cooldown {
+		if !ok {
+			w = &throttleWindow{}
+			t.windows[fingerprint] = w
+		}
+		w.start = now
+		w.count = 0
+	}
+	w.count++
+	if w.count > t.opts.Burst {
+		w.suppressed++
+		w.record = record.Clone()
+		w.err = err
+		t.mu.Unlock()
+		return
+	}
+	t.mu.Unlock()
+
+	t.alertFn(ctx, record, err)
+}
+
+func (t *Throttler) run() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.opts.SummaryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.summarize()
+		case <-t.stop:
+			t.summarize()
+			return
+		}
+	}
+}
+
+// summarize sends a summary alert for each fingerprint with suppressed
+// occurrences and forgets expired fingerprints.
+func (t *Throttler) summarize() {
+	type summary struct {
+		record slog.Record
+		err    error
+	}
+	var summaries []summary
+
+	now := t.now()
+	t.mu.Lock()
+	for fingerprint, w := range t.windows {
+		if w.suppressed > 0 {
+			record := slog.NewRecord(now, w.record.Level, fmt.Sprintf("%s (%d more occurrences suppressed)", w.record.Message, w.suppressed), w.record.PC)
+			w.record.Attrs(func(a slog.Attr) bool {
+				record.AddAttrs(a)
+				return true
+			})
+			record.AddAttrs(slog.Int("suppressed", w.suppressed))
+			summaries = append(summaries, summary{record: record, err: w.err})
+
+			w.suppressed = 0
+			w.record = slog.Record{}
+			w.err = nil
+			continue
+		}
+		if now.Sub(w.start) >= t.opts.Cooldown {
+			delete(t.windows, fingerprint)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, s := range summaries {
+		t.alertFn(context.Background(), s.record, s.err)
+	}
+}
+
+// Close stops the summary goroutine and sends a final summary alert for
+// suppressed occurrences.
+func (t *Throttler) Close() {
+	select {
+	case <-t.stop:
+	default:
+		close(t.stop)
+	}
+	<-t.done
+}
+
+var templateNumbers = regexp.MustCompile(`0x[0-9a-fA-F]+|\d+`)
+
+// Fingerprint identifies an alert by its error message template, the stack
+// frames captured by Errorf/Error and the values of the given record attrs.
+//
+// The message template is the Errorf format string. For wrapped errors,
+// numbers in the error message are masked, so "block 123 not found" and
+// "block 124 not found" share a fingerprint.
+func Fingerprint(err error, record slog.Record, attrs ...string) string {
+	h := fnv.New64a()
+
+	var ae *alertError
+	if errors.As(err, &ae) {
+		if ae.format != "" {
+			h.Write([]byte(ae.format))
+		} else {
+			h.Write([]byte(templateNumbers.ReplaceAllString(ae.Error(), "#")))
+		}
+		for _, pc := range ae.StackFrames() {
+			h.Write(strconv.AppendUint(nil, uint64(pc), 16))
+			h.Write([]byte{0})
+		}
+	} else if err != nil {
+		h.Write([]byte(templateNumbers.ReplaceAllString(err.Error(), "#")))
+	}
+
+	for _, key := range attrs {
+		record.Attrs(func(a slog.Attr) bool {
+			if a.Key == key {
+				h.Write([]byte(key + "=" + a.Value.String() + "\x00"))
+				return false
+			}
+			return true
+		})
+	}
+
+	return strconv.FormatUint(h.Sum64(), 16)
+}
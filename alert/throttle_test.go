@@ -0,0 +1,109 @@
+package alert
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordedAlert struct {
+	record slog.Record
+	err    error
+}
+
+type alertRecorder struct {
+	mu     sync.Mutex
+	alerts []recordedAlert
+}
+
+func (r *alertRecorder) Alert(ctx context.Context, record slog.Record, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.alerts = append(r.alerts, recordedAlert{record: record, err: err})
+}
+
+func (r *alertRecorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.alerts)
+}
+
+func TestThrottler_SuppressesDuplicates(t *testing.T) {
+	var rec alertRecorder
+	throttler := NewThrottler(rec.Alert, ThrottleOptions{Cooldown: time.Minute, Burst: 2, SummaryInterval: time.Hour})
+
+	now := time.Now()
+	throttler.now = func() time.Time { return now }
+
+	logger := slog.New(LogHandler(slog.NewTextHandler(io.Discard, nil), throttler.Alert))
+	for i := range 10 {
+		logger.Error("failed", slog.Any("error", Errorf("block %d not found", i)))
+	}
+	if got := rec.Len(); got != 2 {
+		t.Fatalf("expected 2 alerts within burst, got %d", got)
+	}
+
+	// Different call site is a different fingerprint.
+	logger.Error("failed", slog.Any("error", Errorf("block %d not found", 1)))
+	if got := rec.Len(); got != 3 {
+		t.Fatalf("expected alert from another call site, got %d alerts", got)
+	}
+
+	throttler.Close()
+	if got := rec.Len(); got != 4 {
+		t.Fatalf("expected summary alert on Close, got %d alerts", got)
+	}
+	summary := rec.alerts[3].record
+	if !strings.Contains(summary.Message, "8 more occurrences suppressed") {
+		t.Errorf("unexpected summary message %q", summary.Message)
+	}
+	var suppressed int64
+	summary.Attrs(func(a slog.Attr) bool {
+		if a.Key == "suppressed" {
+			suppressed = a.Value.Int64()
+		}
+		return true
+	})
+	if suppressed != 8 {
+		t.Errorf("expected suppressed=8 attr, got %d", suppressed)
+	}
+}
+
+func TestThrottler_CooldownExpires(t *testing.T) {
+	var rec alertRecorder
+	throttler := NewThrottler(rec.Alert, ThrottleOptions{Cooldown: time.Minute, SummaryInterval: time.Hour})
+	defer throttler.Close()
+
+	now := time.Now()
+	throttler.now = func() time.Time { return now }
+
+	for i := range 3 {
+		if i == 2 {
+			now = now.Add(2 * time.Minute)
+		}
+		throttler.Alert(context.Background(), slog.NewRecord(now, slog.LevelError, "failed", 0), Errorf("timeout"))
+	}
+
+	if got := rec.Len(); got != 2 {
+		t.Fatalf("expected alert after cooldown, got %d alerts", got)
+	}
+}
+
+func TestFingerprint_Attrs(t *testing.T) {
+	err := Errorf("timeout")
+	record1 := slog.NewRecord(time.Now(), slog.LevelError, "failed", 0)
+	record1.AddAttrs(slog.Int("chainId", 1))
+	record2 := slog.NewRecord(time.Now(), slog.LevelError, "failed", 0)
+	record2.AddAttrs(slog.Int("chainId", 137))
+
+	if Fingerprint(err, record1) != Fingerprint(err, record2) {
+		t.Error("expected same fingerprint without attrs")
+	}
+	if Fingerprint(err, record1, "chainId") == Fingerprint(err, record2, "chainId") {
+		t.Error("expected different fingerprints for different chainId")
+	}
+}
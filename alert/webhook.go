@@ -0,0 +1,298 @@
+package alert
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-chi/traceid"
+
+	"github.com/0xsequence/go-libs/config"
+)
+
+type WebhookFormat string
+
+const (
+	WebhookJSON     WebhookFormat = "json"     // WebhookPayload as JSON (default).
+	WebhookSlack    WebhookFormat = "slack"    // Slack incoming webhook message.
+	WebhookTemplate WebhookFormat = "template" // WebhookOptions.Template executed with WebhookPayload.
+)
+
+// SignatureHeader carries the hex-encoded HMAC-SHA256 of the request body,
+// prefixed with "sha256=", when WebhookOptions.Secret is set.
+const SignatureHeader = "X-Signature-256"
+
+// WebhookOptions configures the webhook alert sink, see NewWebhook.
+type WebhookOptions struct {
+	URL    string
+	Format WebhookFormat
+
+	// Template is a text/template executed with WebhookPayload as the
+	// request body. Required for WebhookTemplate. Values are not escaped;
+	// use the "json" func to render JSON values, including quotes:
+	//
+	//	{"text": {{json .Message}}, "severity": {{json .Severity}}}
+	Template    string
+	ContentType string // Defaults to "application/json".
+
+	Headers map[string]string // Static request headers, e.g. Authorization.
+	Secret  string            // HMAC-SHA256 signing key. Signing is disabled if empty.
+
+	Service string
+	Env     config.Env
+
+	Retries int           // Number of retries on network errors, 429 and 5xx responses. Defaults to 3, negative disables retries.
+	Backoff time.Duration // Delay before the first retry, doubled for each following retry. Defaults to 500ms.
+
+	Client *http.Client // Defaults to a client with 10s timeout.
+}
+
+// WebhookPayload describes an alert sent to the webhook.
+type WebhookPayload struct {
+	Time     time.Time      `json:"time"`
+	Message  string         `json:"message"`
+	Error    string         `json:"error"`
+	Severity string         `json:"severity"`
+	Owner    string         `json:"owner,omitempty"`
+	Tags     []string       `json:"tags,omitempty"`
+	Caller   string         `json:"caller,omitempty"`
+	Stack    []string       `json:"stack,omitempty"`
+	TraceID  string         `json:"traceId,omitempty"`
+	Service  string         `json:"service,omitempty"`
+	Env      string         `json:"env"`
+	Attrs    map[string]any `json:"attrs,omitempty"`
+}
+
+// Webhook is an alert sink POSTing alerts to an HTTP endpoint.
+//
+//	webhook, err := alert.NewWebhook(alert.WebhookOptions{
+//		URL:     cfg.Alerts.SlackURL,
+//		Format:  alert.WebhookSlack,
+//		Service: "api",
+//		Env:     cfg.Env,
+//	})
+//
+// Send blocks for up to Retries+1 requests with backoff, so use it through
+// a Dispatcher, not directly from alert.LogHandler:
+//
+//	dispatcher := alert.NewDispatcher(alert.DispatcherOptions{},
+//		alert.Sink{Name: "slack", Alert: webhook.Alert},
+//	)
+//	defer dispatcher.Shutdown(context.Background())
+//
+//	slogHandler = alert.LogHandler(slogHandler, dispatcher.Alert)
+type Webhook struct {
+	opts     WebhookOptions
+	template *template.Template
+}
+
+func NewWebhook(opts WebhookOptions) (*Webhook, error) {
+	if opts.URL == "" {
+		return nil, fmt.Errorf("webhook: url is required")
+	}
+	opts.Format = cmp.Or(opts.Format, WebhookJSON)
+	opts.ContentType = cmp.Or(opts.ContentType, "application/json")
+	switch {
+	case opts.Retries == 0:
+		opts.Retries = 3
+	case opts.Retries < 0:
+		opts.Retries = 0
+	}
+	opts.Backoff = cmp.Or(opts.Backoff, 500*time.Millisecond)
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	w := &Webhook{opts: opts}
+
+	switch opts.Format {
+	case WebhookJSON, WebhookSlack:
+	case WebhookTemplate:
+		if opts.Template == "" {
+			return nil, fmt.Errorf("webhook: template is required for %q format", opts.Format)
+		}
+		tmpl, err := template.New("webhook").Funcs(template.FuncMap{"json": templateJSON}).Parse(opts.Template)
+		if err != nil {
+			return nil, fmt.Errorf("webhook: parse template: %w", err)
+		}
+		w.template = tmpl
+	default:
+		return nil, fmt.Errorf("webhook: unknown format %q", opts.Format)
+	}
+
+	return w, nil
+}
+
+// Alert sends the alert to the webhook. It is a side-effect hook for
+// alert.LogHandler and ignores delivery errors; use Send to handle them.
+func (w *Webhook) Alert(ctx context.Context, record slog.Record, err error) {
+	_ = w.Send(ctx, w.Payload(ctx, record, err))
+}
+
+// Payload builds the webhook payload from the alert callback arguments.
+func (w *Webhook) Payload(ctx context.Context, record slog.Record, err error) WebhookPayload {
+	p := WebhookPayload{
+		Time:     record.Time,
+		Message:  record.Message,
+		Severity: SeverityOf(err).String(),
+		Owner:    OwnerOf(err),
+		Tags:     TagsOf(err),
+		Stack:    stackStrings(StackTrace(err)),
+		TraceID:  traceid.FromContext(ctx),
+		Service:  w.opts.Service,
+		Env:      w.opts.Env.String(),
+		Attrs:    attrsMap(record),
+	}
+	if err != nil {
+		p.Error = err.Error()
+	}
+	if record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		p.Caller = fmt.Sprintf("%s:%d %s", frame.File, frame.Line, frame.Function)
+	}
+	return p
+}
+
+// Send POSTs the payload, retrying on network errors, 429 and 5xx responses.
+func (w *Webhook) Send(ctx context.Context, p WebhookPayload) error {
+	body, err := w.body(p)
+	if err != nil {
+		return err
+	}
+
+	backoff := w.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := w.post(ctx, body)
+		if err == nil || !retry || attempt >= w.opts.Retries {
+			return err
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", w.opts.ContentType)
+	for k, v := range w.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	if w.opts.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.opts.Secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := w.opts.Client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook: unexpected status %d", resp.StatusCode)
+}
+
+func (w *Webhook) body(p WebhookPayload) ([]byte, error) {
+	switch w.opts.Format {
+	case WebhookSlack:
+		return json.Marshal(map[string]string{"text": slackText(p)}) //nolint:wrapcheck
+	case WebhookTemplate:
+		var buf bytes.Buffer
+		if err := w.template.Execute(&buf, p); err != nil {
+			return nil, fmt.Errorf("webhook: execute template: %w", err)
+		}
+		return buf.Bytes(), nil
+	default:
+		return json.Marshal(p) //nolint:wrapcheck
+	}
+}
+
+// templateJSON renders v as JSON, e.g. a quoted and escaped string.
+func templateJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err //nolint:wrapcheck
+}
+
+func slackText(p WebhookPayload) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*[%s] %s*", strings.ToUpper(p.Severity), p.Message)
+	if p.Service != "" {
+		fmt.Fprintf(&b, " (%s@%s)", p.Service, p.Env)
+	}
+	fmt.Fprintf(&b, "\n```%s```", p.Error)
+	if p.Caller != "" {
+		fmt.Fprintf(&b, "\n*Caller:* `%s`", p.Caller)
+	}
+	if p.TraceID != "" {
+		fmt.Fprintf(&b, "\n*Trace ID:* `%s`", p.TraceID)
+	}
+	if p.Owner != "" {
+		fmt.Fprintf(&b, "\n*Owner:* %s", p.Owner)
+	}
+	if len(p.Tags) > 0 {
+		fmt.Fprintf(&b, "\n*Tags:* %s", strings.Join(p.Tags, ", "))
+	}
+	if len(p.Stack) > 0 {
+		fmt.Fprintf(&b, "\n```%s```", strings.Join(p.Stack, "\n"))
+	}
+	return b.String()
+}
+
+// attrsMap converts record attrs to a JSON-friendly map, with groups as
+// nested maps. The "stack" attr added by LogHandler is skipped, since it is
+// sent as WebhookPayload.Stack.
+func attrsMap(record slog.Record) map[string]any {
+	var attrs []slog.Attr
+	record.Attrs(func(a slog.Attr) bool {
+		if a.Key != "stack" {
+			attrs = append(attrs, a)
+		}
+		return true
+	})
+	if len(attrs) == 0 {
+		return nil
+	}
+	return groupMap(attrs)
+}
+
+func groupMap(attrs []slog.Attr) map[string]any {
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		switch v.Kind() {
+		case slog.KindGroup:
+			m[a.Key] = groupMap(v.Group())
+		case slog.KindAny:
+			m[a.Key] = v.String()
+		default:
+			m[a.Key] = v.Any()
+		}
+	}
+	return m
+}
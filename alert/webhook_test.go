@@ -0,0 +1,204 @@
+package alert
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/traceid"
+
+	"github.com/0xsequence/go-libs/config"
+)
+
+func TestWebhook_JSON(t *testing.T) {
+	var attempts atomic.Int32
+	var got WebhookPayload
+	var gotSignature string
+	var gotBody []byte
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		_ = json.Unmarshal(gotBody, &got)
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:     srv.URL,
+		Secret:  "s3cr3t",
+		Service: "api",
+		Env:     config.EnvProd,
+		Backoff: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logger := slog.New(LogHandler(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{AddSource: true}), webhook.Alert))
+	ctx := traceid.NewContext(context.Background())
+	logger.ErrorContext(ctx, "failed", slog.Int("chainId", 137), slog.Any("error", Critical(Errorf("timeout"), Owner("infra"))))
+
+	if attempts.Load() != 2 {
+		t.Fatalf("expected 1 retry, got %d attempts", attempts.Load())
+	}
+	if got.Message != "failed" || got.Error != "timeout" || got.Severity != "critical" || got.Owner != "infra" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+	if got.Service != "api" || got.Env != "prod" || got.TraceID != traceid.FromContext(ctx) {
+		t.Errorf("unexpected payload %+v", got)
+	}
+	if !strings.Contains(got.Caller, "webhook_test.go") || len(got.Stack) == 0 {
+		t.Errorf("expected caller and stack, got %+v", got)
+	}
+	if got.Attrs["chainId"] != float64(137) {
+		t.Errorf("expected chainId attr, got %+v", got.Attrs)
+	}
+	if _, ok := got.Attrs["stack"]; ok {
+		t.Error("expected stack only in payload.Stack, not in attrs")
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cr3t"))
+	mac.Write(gotBody)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); gotSignature != want {
+		t.Errorf("expected signature %q, got %q", want, gotSignature)
+	}
+}
+
+func TestWebhook_Slack(t *testing.T) {
+	var got struct {
+		Text string `json:"text"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{URL: srv.URL, Format: WebhookSlack})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := webhook.Send(context.Background(), WebhookPayload{Message: "failed", Error: "timeout", Severity: "error"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(got.Text, "*[ERROR] failed*") || !strings.Contains(got.Text, "timeout") {
+		t.Errorf("unexpected slack text %q", got.Text)
+	}
+}
+
+func TestWebhook_Template(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = string(body)
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:         srv.URL,
+		Format:      WebhookTemplate,
+		Template:    `{{.Severity}}: {{.Message}} ({{.Error}})`,
+		ContentType: "text/plain",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := webhook.Send(context.Background(), WebhookPayload{Message: "failed", Error: "timeout", Severity: "warning"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "warning: failed (timeout)" {
+		t.Errorf("unexpected body %q", got)
+	}
+}
+
+func TestWebhook_TemplateJSON(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("invalid JSON body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{
+		URL:      srv.URL,
+		Format:   WebhookTemplate,
+		Template: `{"text": {{json .Message}}, "tags": {{json .Tags}}}`,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	msg := "failed to parse \"id\"\n"
+	if err := webhook.Send(context.Background(), WebhookPayload{Message: msg, Tags: []string{"db"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got["text"] != msg {
+		t.Errorf("unexpected text %q", got["text"])
+	}
+}
+
+func TestWebhook_NoRetries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{URL: srv.URL, Retries: -1, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := webhook.Send(context.Background(), WebhookPayload{}); err == nil {
+		t.Fatal("expected error")
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Errorf("expected 1 attempt, got %d", got)
+	}
+}
+
+func TestWebhook_NoRetryOnClientError(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	webhook, err := NewWebhook(WebhookOptions{URL: srv.URL, Backoff: time.Millisecond})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := webhook.Send(context.Background(), WebhookPayload{}); err == nil {
+		t.Fatal("expected error on 400 response")
+	}
+	if attempts.Load() != 1 {
+		t.Errorf("expected no retries, got %d attempts", attempts.Load())
+	}
+}
+
+func TestNewWebhook_InvalidOptions(t *testing.T) {
+	for _, opts := range []WebhookOptions{
+		{},
+		{URL: "http://localhost", Format: "xml"},
+		{URL: "http://localhost", Format: WebhookTemplate},
+		{URL: "http://localhost", Format: WebhookTemplate, Template: "{{"},
+	} {
+		if _, err := NewWebhook(opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
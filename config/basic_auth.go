@@ -2,5 +2,5 @@ package config
 
 type BasicAuth struct {
 	Username string `toml:"username"`
-	Password string `toml:"password"`
+	Password Secret `toml:"password"` // Can be a secret reference, e.g. "env:DEBUG_PASSWORD".
 }
@@ -0,0 +1,219 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-chi/transport"
+)
+
+// Client returns an HTTP client configured from the service: timeout,
+// retries, rate limit, static headers, TLS and proxy. It also sends the
+// access key as X-Access-Key header, or the JWT token as Authorization
+// header, logs requests in CURL format if DebugRequests is set, and dials
+// the socket of unix:// URLs.
+//
+// Signing JWT tokens with JWTSecret is left to the caller.
+func (s *Service) Client() (*http.Client, error) {
+	base := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert
+
+	tlsConfig, err := s.TLS.config()
+	if err != nil {
+		return nil, err
+	}
+	base.TLSClientConfig = tlsConfig
+
+	if socket := s.url.SocketPath(); socket != "" {
+		base.Proxy = nil
+		base.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+	}
+
+	if s.Proxy != "" {
+		proxy, err := url.Parse(s.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		base.Proxy = http.ProxyURL(proxy)
+	}
+
+	headers := http.Header{}
+	for key, value := range s.Headers {
+		headers.Set(key, value)
+	}
+
+	var rt http.RoundTripper = base
+	if s.RateLimit > 0 {
+		rt = rateLimitTransport(rt, s.RateLimit)
+	}
+	if s.Retries > 0 {
+		rt = retryTransport(rt, s.Retries)
+	}
+	if len(headers) > 0 {
+		rt = headerTransport(rt, headers)
+	}
+	switch {
+	case s.AccessKey.IsSet():
+		rt = secretTransport(rt, "X-Access-Key", "", s.AccessKey)
+	case s.JWTToken.IsSet():
+		rt = secretTransport(rt, "Authorization", "BEARER ", s.JWTToken)
+	}
+	if s.DebugRequests {
+		rt = transport.LogRequests(transport.LogOptions{CURL: true})(rt)
+	}
+
+	return &http.Client{
+		Transport: rt,
+		Timeout:   s.Timeout,
+	}, nil
+}
+
+func (t ServiceTLS) config() (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: t.InsecureSkipVerify, //nolint:gosec // Allowed in local and test envs only, see Service.ValidateEnv.
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls.ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("failed to parse tls.ca_file %q: no PEM certificates", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls.cert_file and tls.key_file: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
+
+func headerTransport(next http.RoundTripper, headers http.Header) http.RoundTripper {
+	return transport.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		r := transport.CloneRequest(req)
+		for key, values := range headers {
+			if r.Header.Get(key) == "" {
+				r.Header[key] = values
+			}
+		}
+		return next.RoundTrip(r)
+	})
+}
+
+// secretTransport sets the header to prefix and the secret value, unless
+// the request already has it. The secret is read on each request, so a
+// reloaded secret is sent without rebuilding the client.
+func secretTransport(next http.RoundTripper, key, prefix string, secret Secret) http.RoundTripper {
+	return transport.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Header.Get(key) != "" {
+			return next.RoundTrip(req)
+		}
+		r := transport.CloneRequest(req)
+		r.Header.Set(key, prefix+secret.Value())
+		return next.RoundTrip(r)
+	})
+}
+
+// retryTransport retries idempotent requests, and requests with a
+// replayable body, on network errors, 429 and 5xx responses with
+// exponential backoff starting at 100ms.
+func retryTransport(next http.RoundTripper, retries int) http.RoundTripper {
+	return transport.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		backoff := 100 * time.Millisecond
+		for attempt := 0; attempt < retries && shouldRetry(req, resp, err); attempt++ {
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err() //nolint:wrapcheck
+			case <-time.After(backoff):
+				backoff *= 2
+			}
+
+			r := req
+			if req.Body != nil && req.Body != http.NoBody {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err //nolint:wrapcheck
+				}
+				r = transport.CloneRequest(req)
+				r.Body = body
+			}
+			resp, err = next.RoundTrip(r)
+		}
+		return resp, err //nolint:wrapcheck
+	})
+}
+
+func shouldRetry(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		if req.GetBody == nil {
+			return false
+		}
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// rateLimitTransport spaces requests evenly to at most rps requests per
+// second.
+func rateLimitTransport(next http.RoundTripper, rps float64) http.RoundTripper {
+	interval := time.Duration(float64(time.Second) / rps)
+	var mu sync.Mutex
+	var nextAt time.Time
+
+	return transport.RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		now := time.Now()
+		if nextAt.Before(now) {
+			nextAt = now
+		}
+		wait := nextAt.Sub(now)
+		nextAt = nextAt.Add(interval)
+		mu.Unlock()
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err() //nolint:wrapcheck
+			case <-timer.C:
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
@@ -0,0 +1,242 @@
+package config
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/test-go/testify/assert"
+)
+
+func TestServiceClient(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "%s|%s|%s", r.Header.Get("X-Access-Key"), r.Header.Get("X-Client"), r.Header.Get("User-Agent"))
+	}))
+	defer srv.Close()
+
+	svc, err := NewService(srv.URL, WithAccessKey("key"))
+	assert.NoError(t, err)
+	svc.Retries = 2
+	svc.Timeout = 5 * time.Second
+	svc.Headers = map[string]string{"X-Client": "api", "User-Agent": "default"}
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, client.Timeout)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("User-Agent", "custom")
+	resp, err := client.Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body := make([]byte, 64)
+	n, _ := resp.Body.Read(body)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "key|api|custom", string(body[:n]))
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestServiceClientSecretReload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "token")
+	writeFile(t, path, "old")
+	token, err := NewSecret("file:" + path)
+	assert.NoError(t, err)
+	svc, err := NewService(srv.URL)
+	assert.NoError(t, err)
+	svc.JWTToken = token
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+	get := func() string {
+		resp, err := client.Get(srv.URL)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+	assert.Equal(t, "BEARER old", get())
+
+	writeFile(t, path, "new")
+	assert.NoError(t, svc.JWTToken.Reload())
+	assert.Equal(t, "BEARER new", get())
+}
+
+func TestServiceClientNoRetryPOST(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	svc, err := NewService(srv.URL)
+	assert.NoError(t, err)
+	svc.Retries = 3
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+	resp, err := client.Post(srv.URL, "text/plain", http.NoBody)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestServiceClientUnixSocket(t *testing.T) {
+	// Socket paths are limited to ~100 bytes, t.TempDir() may be too long.
+	dir, err := os.MkdirTemp("", "sock")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "api.sock")
+
+	ln, err := net.Listen("unix", socket)
+	assert.NoError(t, err)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	srv.Listener = ln
+	srv.Start()
+	defer srv.Close()
+
+	svc, err := NewService("unix://" + socket)
+	assert.NoError(t, err)
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+	resp, err := client.Get(svc.URL().JoinPath("rpc", "Ping").String())
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, "/rpc/Ping", string(body))
+}
+
+func TestServiceClientRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	svc, err := NewService(srv.URL)
+	assert.NoError(t, err)
+	svc.RateLimit = 20
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+
+	start := time.Now()
+	for range 3 {
+		resp, err := client.Get(srv.URL)
+		assert.NoError(t, err)
+		resp.Body.Close()
+	}
+	assert.True(t, time.Since(start) >= 100*time.Millisecond, "expected requests to be spaced by 50ms")
+}
+
+func TestServiceClientTLS(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writeFile(t, caFile, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})))
+
+	svc, err := NewService(srv.URL)
+	assert.NoError(t, err)
+
+	client, err := svc.Client()
+	assert.NoError(t, err)
+	_, err = client.Get(srv.URL)
+	assert.Error(t, err, "expected unknown authority")
+
+	svc.TLS.CAFile = caFile
+	client, err = svc.Client()
+	assert.NoError(t, err)
+	resp, err := client.Get(srv.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	svc.TLS.CAFile = filepath.Join(t.TempDir(), "missing.pem")
+	_, err = svc.Client()
+	assert.Error(t, err)
+}
+
+func TestServiceTOMLClientFields(t *testing.T) {
+	var cfg struct {
+		Service Service `toml:"service"`
+	}
+	_, err := toml.Decode(`
+[service]
+	url = "https://indexer.sequence.app"
+	timeout = "15s"
+	retries = 3
+	rate_limit = 2.5
+	proxy = "socks5://localhost:1080"
+	[service.headers]
+		X-Client = "api"
+	[service.tls]
+		ca_file = "/etc/ssl/ca.pem"
+		cert_file = "/etc/ssl/client.pem"
+		key_file = "/etc/ssl/client.key"
+`, &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, 15*time.Second, cfg.Service.Timeout)
+	assert.Equal(t, 3, cfg.Service.Retries)
+	assert.Equal(t, 2.5, cfg.Service.RateLimit)
+	assert.Equal(t, "socks5://localhost:1080", cfg.Service.Proxy)
+	assert.Equal(t, map[string]string{"X-Client": "api"}, cfg.Service.Headers)
+	assert.Equal(t, ServiceTLS{CAFile: "/etc/ssl/ca.pem", CertFile: "/etc/ssl/client.pem", KeyFile: "/etc/ssl/client.key"}, cfg.Service.TLS)
+
+	for _, invalid := range []string{
+		`timeout = "fast"`,
+		`timeout = "1ns"`,
+		`retries = 100`,
+		`retries = 1.5`,
+		`rate_limit = -1`,
+		`proxy = "ftp://proxy"`,
+		`proxy = "proxy"`,
+		`headers = { X-Client = 1 }`,
+		`tls = { cert_file = "/etc/ssl/client.pem" }`,
+	} {
+		var cfg struct {
+			Service Service `toml:"service"`
+		}
+		_, err := toml.Decode("[service]\nurl = \"https://a.example\"\n"+invalid, &cfg)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestServiceInsecureSkipVerify(t *testing.T) {
+	svc, err := NewService("https://localhost:8443")
+	assert.NoError(t, err)
+	svc.TLS.InsecureSkipVerify = true
+
+	var cfg struct {
+		Service Service `toml:"service"`
+	}
+	cfg.Service = svc
+	assert.NoError(t, Validate(&cfg, EnvLocal))
+	err = Validate(&cfg, EnvProd)
+	if assert.Error(t, err) {
+		assert.Equal(t, "service.tls.insecure_skip_verify: not allowed in prod", err.Error())
+	}
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+type DumpFormat string
+
+const (
+	DumpTOML DumpFormat = "toml"
+	DumpJSON DumpFormat = "json"
+)
+
+// sensitiveKeys mask values of keys containing any of them, ignoring case
+// and "_", "-" and ".". Shared by Dump and the logger redaction. Tokens are
+// listed by kind, since keys like "token_id" or "token_address" aren't
+// sensitive.
+var sensitiveKeys = []string{
+	"password",
+	"secret",
+	"jwt_token",
+	"access_token",
+	"refresh_token",
+	"auth_token",
+	"access_key",
+	"api_key",
+	"private_key",
+	"authorization",
+	"cookie",
+}
+
+// SensitiveKeys returns the key patterns masked by Dump, and by default by
+// the logger redaction, e.g. "password" or "access_key".
+func SensitiveKeys() []string {
+	return slices.Clone(sensitiveKeys)
+}
+
+// IsSensitiveKey reports whether key contains one of SensitiveKeys,
+// ignoring case and "_", "-" and ".", e.g. "JWT_SECRET" or "apiKey".
+func IsSensitiveKey(key string) bool {
+	key = normalizeKey(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, normalizeKey(sensitive)) {
+			return true
+		}
+	}
+	return false
+}
+
+var keyReplacer = strings.NewReplacer("_", "", "-", "", ".", "")
+
+func normalizeKey(key string) string {
+	return keyReplacer.Replace(strings.ToLower(key))
+}
+
+// Dump renders the effective config as TOML or JSON for debugging, e.g.
+// logged at startup or served by DumpHandler. Secret values, string values
+// of sensitive keys (see SensitiveKeys), and URL passwords and sensitive
+// query parameters, e.g. "?access_token=", are masked.
+func Dump(cfg any, format DumpFormat) ([]byte, error) {
+	tree := dumpValue(reflect.ValueOf(cfg), "")
+	if tree == nil {
+		tree = map[string]any{}
+	}
+
+	switch format {
+	case DumpTOML, "":
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(tree); err != nil {
+			return nil, fmt.Errorf("config: dump: %w", err)
+		}
+		return buf.Bytes(), nil
+	case DumpJSON:
+		data, err := json.MarshalIndent(tree, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("config: dump: %w", err)
+		}
+		return data, nil
+	}
+	return nil, fmt.Errorf("config: unknown dump format %q", format)
+}
+
+// DumpHandler serves the config returned by cfg, rendered by Dump. The
+// format is TOML, or JSON with ?format=json. Protect it, e.g. with
+// middleware.BasicAuth(cfg.Debug.BasicAuth).
+//
+//	r.With(middleware.BasicAuth(cfg.Debug.BasicAuth)).Handle("/debug/config", config.DumpHandler(func() any {
+//		return watcher.Get()
+//	}))
+func DumpHandler(cfg func() any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := DumpFormat(r.URL.Query().Get("format"))
+		data, err := Dump(cfg(), format)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if format == DumpJSON {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		}
+		w.Write(data)
+	})
+}
+
+// dumpValue converts v to TOML/JSON-encodable maps, slices and scalars,
+// or nil if v has no value to render.
+func dumpValue(v reflect.Value, key string) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		return dumpValue(v.Elem(), key)
+	}
+
+	switch value := v.Interface().(type) {
+	case Secret:
+		if !value.IsSet() {
+			return nil
+		}
+		return redacted
+	case BaseURL:
+		if value.String() == "" {
+			return nil
+		}
+		return value.String()
+	case Service:
+		m := dumpStruct(v)
+		if u := value.url.String(); u != "" {
+			m["url"] = u
+		}
+		return m
+	case time.Duration:
+		return value.String()
+	case encoding.TextMarshaler:
+		text, err := value.MarshalText()
+		if err != nil {
+			return fmt.Sprintf("<%v>", err)
+		}
+		return string(text)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return dumpStruct(v)
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+		items := make([]any, 0, v.Len())
+		for i := range v.Len() {
+			if item := dumpValue(v.Index(i), key); item != nil {
+				items = append(items, item)
+			}
+		}
+		return items
+	case reflect.Map:
+		if v.Len() == 0 {
+			return nil
+		}
+		m := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			k := fmt.Sprint(iter.Key().Interface())
+			if item := dumpValue(iter.Value(), k); item != nil {
+				m[k] = item
+			}
+		}
+		return m
+	case reflect.String:
+		if v.String() != "" && IsSensitiveKey(key) {
+			return redacted
+		}
+		return redactURL(v.String())
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		return nil
+	}
+	return v.Interface()
+}
+
+func dumpStruct(v reflect.Value) map[string]any {
+	m := map[string]any{}
+	t := v.Type()
+	for i := range t.NumField() {
+		key := tomlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		if value := dumpValue(v.Field(i), key); value != nil {
+			m[key] = value
+		}
+	}
+	return m
+}
@@ -2,12 +2,15 @@ package config
 
 import (
 	"fmt"
+	"math"
 	"slices"
 	"strings"
+	"sync"
 )
 
 // Env defines our application environments (local, test, dev, staging, prod)
 // with utilities for comparison, string conversion, and text marshaling.
+// Additional environments are defined with RegisterEnv.
 type Env uint8
 
 const (
@@ -20,20 +23,133 @@ const (
 	EnvProd
 )
 
-var environments = []string{
-	"local", // 0
-	"test",  // 1
-	"dev",   // 2
-	"dev2",  // 3
-	"next",  // 4
-	"stg",   // 5
-	"prod",  // 6
+// Tier classifies environments, e.g. all production environments share
+// TierProd.
+type Tier uint8
+
+const (
+	TierLocal Tier = iota
+	TierTest
+	TierDev
+	TierStaging
+	TierProd
+)
+
+var tiers = []string{
+	"local",   // 0
+	"test",    // 1
+	"dev",     // 2
+	"staging", // 3
+	"prod",    // 4
+}
+
+func (t Tier) String() string {
+	if int(t) >= len(tiers) {
+		return fmt.Sprintf("Tier(%d)", t)
+	}
+
+	return tiers[t]
+}
+
+type environment struct {
+	name    string
+	tier    Tier
+	aliases []string
+}
+
+var (
+	environmentsMu sync.RWMutex
+	environments   = []environment{
+		{name: "local", tier: TierLocal},                                // 0
+		{name: "test", tier: TierTest},                                  // 1
+		{name: "dev", tier: TierDev},                                    // 2
+		{name: "dev2", tier: TierDev},                                   // 3
+		{name: "next", tier: TierStaging},                               // 4
+		{name: "stg", tier: TierStaging, aliases: []string{"staging"}},  // 5
+		{name: "prod", tier: TierProd, aliases: []string{"production"}}, // 6
+	}
+)
+
+// RegisterEnv defines an additional environment with the given tier and
+// aliases accepted by UnmarshalText. Register environments in init, before
+// loading the config. It panics if the name or an alias is already taken.
+//
+//	var EnvSandbox = config.RegisterEnv("sandbox", config.TierStaging, "sbx")
+func RegisterEnv(name string, tier Tier, aliases ...string) Env {
+	environmentsMu.Lock()
+	defer environmentsMu.Unlock()
+
+	keys := append([]string{name}, aliases...)
+	for i, key := range keys {
+		if key == "" {
+			panic("config: empty env name")
+		}
+		if _, ok := lookupEnv(key); ok || slices.Contains(keys[:i], key) {
+			panic(fmt.Sprintf("config: env %q already registered", key))
+		}
+	}
+	if len(environments) > math.MaxUint8 {
+		panic("config: too many envs")
+	}
+
+	environments = append(environments, environment{name: name, tier: tier, aliases: aliases})
+	return Env(len(environments) - 1)
+}
+
+// ParseEnv returns the environment with the given name or alias.
+func ParseEnv(name string) (Env, bool) {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+	return lookupEnv(name)
+}
+
+func lookupEnv(name string) (Env, bool) {
+	for i, env := range environments {
+		if name == env.name || slices.Contains(env.aliases, name) {
+			return Env(i), true
+		}
+	}
+	return 0, false
+}
+
+// Envs returns all environments, built-in and registered.
+func Envs() []Env {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+
+	envs := make([]Env, len(environments))
+	for i := range environments {
+		envs[i] = Env(i)
+	}
+	return envs
 }
 
 func (e Env) Is(envs ...Env) bool {
 	return slices.Contains(envs, e)
 }
 
+// Tier returns the tier of the environment. Unknown environments are in
+// TierProd, so env-specific checks fail closed.
+func (e Env) Tier() Tier {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+
+	if int(e) >= len(environments) {
+		return TierProd
+	}
+	return environments[e].tier
+}
+
+// IsProduction reports whether the environment is in TierProd.
+func (e Env) IsProduction() bool {
+	return e.Tier() == TierProd
+}
+
+// IsLocal reports whether the environment is in TierLocal.
+func (e Env) IsLocal() bool {
+	return e.Tier() == TierLocal
+}
+
 func (e Env) MarshalText() ([]byte, error) {
 	return []byte(e.String()), nil
 }
@@ -47,20 +163,27 @@ func (e *Env) UnmarshalText(text []byte) error {
 		return nil
 	}
 
-	for i, name := range environments {
-		if enum == name {
-			*e = Env(i)
-			return nil
-		}
+	if env, ok := ParseEnv(enum); ok {
+		*e = env
+		return nil
 	}
 
-	return fmt.Errorf("unknown env=(%s), supported=(%s)", text, strings.Join(environments, ","))
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+	names := make([]string, len(environments))
+	for i, env := range environments {
+		names[i] = env.name
+	}
+	return fmt.Errorf("unknown env=(%s), supported=(%s)", text, strings.Join(names, ","))
 }
 
 func (e Env) String() string {
+	environmentsMu.RLock()
+	defer environmentsMu.RUnlock()
+
 	if int(e) >= len(environments) {
 		return fmt.Sprintf("Env(%d)", e)
 	}
 
-	return environments[e]
+	return environments[e].name
 }
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+func TestEnvUnmarshalText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Env
+	}{
+		{input: "", want: EnvLocal},
+		{input: "local", want: EnvLocal},
+		{input: "dev2", want: EnvDev2},
+		{input: "stg", want: EnvStg},
+		{input: "staging", want: EnvStg},
+		{input: "prod", want: EnvProd},
+		{input: "production", want: EnvProd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			var env Env
+			assert.NoError(t, env.UnmarshalText([]byte(tt.input)))
+			assert.Equal(t, tt.want, env)
+		})
+	}
+
+	var env Env
+	err := env.UnmarshalText([]byte("qa"))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "supported=(local,test,dev,dev2,next,stg,prod")
+	}
+
+	// Aliases marshal to the canonical name.
+	text, err := EnvStg.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "stg", string(text))
+}
+
+func TestEnvTier(t *testing.T) {
+	assert.Equal(t, TierLocal, EnvLocal.Tier())
+	assert.Equal(t, TierTest, EnvTest.Tier())
+	assert.Equal(t, TierDev, EnvDev2.Tier())
+	assert.Equal(t, TierStaging, EnvStg.Tier())
+	assert.Equal(t, TierProd, EnvProd.Tier())
+	assert.Equal(t, "staging", TierStaging.String())
+
+	assert.True(t, EnvLocal.IsLocal())
+	assert.False(t, EnvTest.IsLocal())
+	assert.True(t, EnvProd.IsProduction())
+	assert.False(t, EnvStg.IsProduction())
+
+	assert.Equal(t, "Env(200)", Env(200).String())
+	assert.True(t, Env(200).IsProduction(), "unknown envs fail closed")
+}
+
+// resetEnvs restores the built-in environments after the test.
+func resetEnvs(t *testing.T) {
+	t.Helper()
+	environmentsMu.RLock()
+	saved := slices.Clone(environments)
+	environmentsMu.RUnlock()
+
+	t.Cleanup(func() {
+		environmentsMu.Lock()
+		defer environmentsMu.Unlock()
+		environments = saved
+	})
+}
+
+func TestRegisterEnv(t *testing.T) {
+	resetEnvs(t)
+
+	envSandbox := RegisterEnv("sandbox", TierStaging, "sbx")
+	envLive := RegisterEnv("live", TierProd)
+
+	assert.Equal(t, "sandbox", envSandbox.String())
+	assert.Equal(t, TierStaging, envSandbox.Tier())
+	assert.True(t, envLive.IsProduction())
+	assert.Contains(t, Envs(), envSandbox)
+
+	var env Env
+	assert.NoError(t, env.UnmarshalText([]byte("sbx")))
+	assert.Equal(t, envSandbox, env)
+
+	parsed, ok := ParseEnv("live")
+	assert.True(t, ok)
+	assert.Equal(t, envLive, parsed)
+	_, ok = ParseEnv("qa")
+	assert.False(t, ok)
+
+	assert.Panics(t, func() { RegisterEnv("prod", TierProd) })
+	assert.Panics(t, func() { RegisterEnv("preview", TierStaging, "staging") })
+	assert.Panics(t, func() { RegisterEnv("", TierDev) })
+	assert.Panics(t, func() { RegisterEnv("qa", TierDev, "qa") })
+	assert.Panics(t, func() { RegisterEnv("qa", TierDev, "q", "q") })
+	_, ok = ParseEnv("qa")
+	assert.False(t, ok, "failed registrations are not recorded")
+
+	// Env-specific validation uses tiers of registered envs.
+	cfg := struct {
+		Service Service `toml:"service"`
+	}{}
+	cfg.Service.TLS.InsecureSkipVerify = true
+	assert.Error(t, Validate(&cfg, envLive))
+	assert.NoError(t, Validate(&cfg, RegisterEnv("ci", TierTest)))
+}
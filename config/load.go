@@ -0,0 +1,148 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Options configures Load.
+type Options struct {
+	// Path to the base TOML file, e.g. "etc/config.toml". The file is
+	// required if set. If empty, only env var overrides are applied.
+	Path string
+
+	// Env selects the optional env-specific overlay next to the base file,
+	// e.g. "etc/config.prod.toml" for EnvProd.
+	Env Env
+
+	// EnvPrefix enables env var overrides and is prepended to env var
+	// names, e.g. "API_" maps the "port" field to API_PORT. Overrides are
+	// not applied if empty, so unrelated env vars like PORT or HOME are
+	// never picked up.
+	EnvPrefix string
+
+	// DisableEnv disables env var overrides, even with EnvPrefix set.
+	DisableEnv bool
+}
+
+// Source describes where the value of a config field came from: the path
+// of the TOML file, "env:<NAME>" for env vars, or SourceDefault.
+type Source string
+
+// SourceDefault is the source of fields not set by any file or env var,
+// i.e. keeping the value cfg was pre-populated with.
+const SourceDefault Source = "default"
+
+func envSource(name string) Source {
+	return Source("env:" + name)
+}
+
+// IsEnv reports whether the value was set by an env var.
+func (s Source) IsEnv() bool {
+	return strings.HasPrefix(string(s), "env:")
+}
+
+// Sources maps TOML keys (e.g. "services.indexer.url") to their source.
+type Sources map[string]Source
+
+// Of returns the source of the TOML key, or SourceDefault.
+func (s Sources) Of(key string) Source {
+	if src, ok := s[key]; ok {
+		return src
+	}
+	return SourceDefault
+}
+
+// Keys returns the keys not set from defaults, sorted.
+func (s Sources) Keys() []string {
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Load populates cfg, a pointer to a struct with toml tags, from the
+// following sources. Each source overrides the fields set by the previous
+// ones:
+//
+//  1. defaults: the values cfg is pre-populated with
+//  2. the base TOML file (opts.Path)
+//  3. the env-specific overlay, e.g. config.prod.toml (if it exists)
+//  4. env var overrides with opts.EnvPrefix, see ApplyEnv
+//
+// It then validates cfg, see Validate, and returns the source of each field
+// set by 2-4.
+//
+//	cfg := Config{Port: 8080}
+//	sources, err := config.Load(&cfg, config.Options{Path: "etc/config.toml", Env: env})
+func Load(cfg any, opts Options) (Sources, error) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: expected pointer to struct, got %T", cfg)
+	}
+
+	sources := Sources{}
+
+	if opts.Path != "" {
+		if err := decodeFile(cfg, opts.Path, sources); err != nil {
+			return nil, err
+		}
+
+		overlay := OverlayPath(opts.Path, opts.Env)
+		if err := decodeFile(cfg, overlay, sources); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if opts.EnvPrefix != "" && !opts.DisableEnv {
+		envSources, err := ApplyEnv(cfg, opts.EnvPrefix)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(sources, envSources)
+	}
+
+	if err := Validate(cfg, opts.Env); err != nil {
+		return nil, fmt.Errorf("config: invalid config:\n%w", err)
+	}
+
+	return sources, nil
+}
+
+// OverlayPath returns the path of the env-specific overlay for the base
+// config file, e.g. "etc/config.prod.toml" for "etc/config.toml".
+func OverlayPath(path string, env Env) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + env.String() + ext
+}
+
+func decodeFile(cfg any, path string, sources Sources) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+	md, err := toml.Decode(string(data), cfg)
+	if err != nil {
+		return fmt.Errorf("config: %s: %w", path, err)
+	}
+	for _, key := range md.Keys() {
+		switch md.Type(key...) {
+		case "Hash", "ArrayHash":
+			// Tables are not fields.
+		default:
+			sources[key.String()] = Source(path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+type testConfig struct {
+	Env     Env     `toml:"env"`
+	Port    int     `toml:"port"`
+	Name    string  `toml:"name"`
+	Ratio   float64 `toml:"ratio"`
+	Verbose bool    `toml:"verbose"`
+
+	Services struct {
+		Indexer  Service `toml:"indexer"`
+		Metadata Service `toml:"metadata"`
+	} `toml:"services"`
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	assert.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.toml")
+	overlay := filepath.Join(dir, "config.prod.toml")
+
+	writeFile(t, base, `
+env = "prod"
+name = "api"
+ratio = 0.5
+
+[services.indexer]
+	url = "http://localhost:4242"
+	access_key = "key"
+`)
+	writeFile(t, overlay, `
+ratio = 0.9
+
+[services.metadata]
+	url = "https://metadata.sequence.app"
+`)
+	t.Setenv("TEST_PORT", "9090")
+	t.Setenv("TEST_VERBOSE", "true")
+
+	cfg := testConfig{Port: 8080, Name: "default"}
+	sources, err := Load(&cfg, Options{Path: base, Env: EnvProd, EnvPrefix: "TEST_"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, EnvProd, cfg.Env)
+	assert.Equal(t, 9090, cfg.Port)
+	assert.Equal(t, "api", cfg.Name)
+	assert.Equal(t, 0.9, cfg.Ratio)
+	assert.True(t, cfg.Verbose)
+	assert.Equal(t, "http://localhost:4242", cfg.Services.Indexer.URL().String())
+	assert.Equal(t, "key", cfg.Services.Indexer.AccessKey.Value())
+	assert.Equal(t, "https://metadata.sequence.app", cfg.Services.Metadata.URL().String())
+
+	assert.Equal(t, Source(base), sources.Of("name"))
+	assert.Equal(t, Source(base), sources.Of("services.indexer.url"))
+	assert.Equal(t, Source(overlay), sources.Of("ratio"))
+	assert.Equal(t, Source(overlay), sources.Of("services.metadata.url"))
+	assert.Equal(t, Source("env:TEST_PORT"), sources.Of("port"))
+	assert.True(t, sources.Of("port").IsEnv())
+	assert.Equal(t, SourceDefault, sources.Of("services.metadata.access_key"))
+	assert.NotContains(t, sources.Keys(), "services")
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("missing base file", func(t *testing.T) {
+		var cfg testConfig
+		_, err := Load(&cfg, Options{Path: filepath.Join(dir, "missing.toml")})
+		assert.True(t, errors.Is(err, os.ErrNotExist), err)
+	})
+
+	t.Run("missing overlay is ok", func(t *testing.T) {
+		base := filepath.Join(dir, "base.toml")
+		writeFile(t, base, `port = 1`)
+
+		var cfg testConfig
+		_, err := Load(&cfg, Options{Path: base, Env: EnvDev})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, cfg.Port)
+	})
+
+	t.Run("invalid service in overlay", func(t *testing.T) {
+		base := filepath.Join(dir, "svc.toml")
+		writeFile(t, base, "[services.indexer]\naccess_key = \"key\"")
+		writeFile(t, OverlayPath(base, EnvStg), "[services.indexer]\njwt_token = \"token\"")
+
+		var cfg testConfig
+		_, err := Load(&cfg, Options{Path: base, Env: EnvStg})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "mutually exclusive")
+		}
+	})
+
+	t.Run("invalid env var", func(t *testing.T) {
+		t.Setenv("TEST_PORT", "abc")
+
+		var cfg testConfig
+		_, err := Load(&cfg, Options{EnvPrefix: "TEST_"})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "TEST_PORT")
+		}
+	})
+
+	t.Run("no env overrides without prefix", func(t *testing.T) {
+		t.Setenv("PORT", "abc")
+
+		cfg := testConfig{Port: 8080}
+		sources, err := Load(&cfg, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, 8080, cfg.Port)
+		assert.Empty(t, sources)
+	})
+
+	t.Run("not a pointer to struct", func(t *testing.T) {
+		_, err := Load(testConfig{}, Options{})
+		assert.Error(t, err)
+	})
+}
+
+func TestOverlayPath(t *testing.T) {
+	assert.Equal(t, "etc/config.prod.toml", OverlayPath("etc/config.toml", EnvProd))
+	assert.Equal(t, "config.local", OverlayPath("config", EnvLocal))
+}
@@ -0,0 +1,387 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	tomlUnmarshalerType = reflect.TypeFor[toml.Unmarshaler]()
+	durationType        = reflect.TypeFor[time.Duration]()
+)
+
+// ApplyEnv overrides fields of cfg, a pointer to a struct with toml tags,
+// with env vars named after their TOML keys: the prefix followed by the
+// upper-cased key path joined with "_", e.g. SERVICES_INDEXER_URL for
+//
+//	Services struct {
+//		Indexer config.Service `toml:"indexer"`
+//	} `toml:"services"`
+//
+// Supported fields are strings, bools, numbers, time.Duration,
+// encoding.TextUnmarshaler types (e.g. Env, BaseURL, slog.Level), pointers
+// to these, and slices of these as comma-separated values. Structs
+// implementing toml.Unmarshaler (e.g. Service) receive all overrides of
+// their fields in a single UnmarshalTOML call, so their validation runs.
+//
+// Maps, e.g. map[string]Service, are not supported, since their keys can't
+// be derived from env var names. ApplyEnv returns an error if an env var
+// would override a map or one of its entries, instead of ignoring it.
+//
+// It returns the source of each overridden field.
+func ApplyEnv(cfg any, prefix string) (Sources, error) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config: expected pointer to struct, got %T", cfg)
+	}
+
+	o := &envOverrider{prefix: prefix, sources: Sources{}}
+	if err := o.applyStruct(rv.Elem(), ""); err != nil {
+		return nil, err
+	}
+	return o.sources, nil
+}
+
+type envOverrider struct {
+	prefix  string
+	sources Sources
+}
+
+func (o *envOverrider) applyStruct(v reflect.Value, path string) error {
+	t := v.Type()
+	var keys []string
+	for i := range t.NumField() {
+		if key := tomlKey(t.Field(i)); key != "" {
+			keys = append(keys, joinKey(path, key))
+		}
+	}
+	for i := range t.NumField() {
+		key := tomlKey(t.Field(i))
+		if key == "" {
+			continue
+		}
+		key = joinKey(path, key)
+		if isMap(t.Field(i).Type) {
+			if err := o.checkMap(key, keys); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := o.apply(v.Field(i), key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (o *envOverrider) apply(v reflect.Value, key string) error {
+	t := v.Type()
+	switch {
+	case isText(t) || isScalar(t):
+		return o.set(v, key)
+
+	case t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(tomlUnmarshalerType):
+		return o.applyUnmarshaler(v, key)
+
+	case t.Kind() == reflect.Struct:
+		return o.applyStruct(v, key)
+
+	case t.Kind() == reflect.Pointer:
+		if !v.IsNil() {
+			return o.apply(v.Elem(), key)
+		}
+		if !isText(t.Elem()) && !isScalar(t.Elem()) {
+			return nil // Don't allocate nil structs.
+		}
+		elem := reflect.New(t.Elem())
+		if err := o.set(elem.Elem(), key); err != nil {
+			return err
+		}
+		if _, ok := o.sources[key]; ok {
+			v.Set(elem)
+		}
+		return nil
+
+	case t.Kind() == reflect.Slice && (isText(t.Elem()) || isScalar(t.Elem())):
+		name := o.envName(key)
+		s, ok := os.LookupEnv(name)
+		if !ok {
+			return nil
+		}
+		items := splitList(s)
+		slice := reflect.MakeSlice(t, len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), item); err != nil {
+				return fmt.Errorf("config: env %s: %w", name, err)
+			}
+		}
+		v.Set(slice)
+		o.sources[key] = envSource(name)
+	}
+	return nil
+}
+
+// set sets a scalar or TextUnmarshaler value from its env var, if set.
+func (o *envOverrider) set(v reflect.Value, key string) error {
+	name := o.envName(key)
+	s, ok := os.LookupEnv(name)
+	if !ok {
+		return nil
+	}
+	if err := setValue(v, s); err != nil {
+		return fmt.Errorf("config: env %s: %w", name, err)
+	}
+	o.sources[key] = envSource(name)
+	return nil
+}
+
+// applyUnmarshaler collects the env vars of all fields of v, including
+// unexported fields with toml tags, and passes them to v.UnmarshalTOML
+// as a TOML table.
+func (o *envOverrider) applyUnmarshaler(v reflect.Value, key string) error {
+	table := map[string]any{}
+	var names []string
+	if err := o.collect(v.Type(), key, table, &names); err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	u := v.Addr().Interface().(toml.Unmarshaler) //nolint:forcetypeassert
+	if err := u.UnmarshalTOML(table); err != nil {
+		return fmt.Errorf("config: env %s: %w", strings.Join(names, ", "), err)
+	}
+	return nil
+}
+
+func (o *envOverrider) collect(t reflect.Type, path string, table map[string]any, names *[]string) error {
+	var keys []string
+	for i := range t.NumField() {
+		if name, _, _ := strings.Cut(t.Field(i).Tag.Get("toml"), ","); name != "" && name != "-" {
+			keys = append(keys, joinKey(path, name))
+		}
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		key := joinKey(path, name)
+
+		ft := field.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Map {
+			if err := o.checkMap(key, keys); err != nil {
+				return err
+			}
+			continue
+		}
+		if ft.Kind() == reflect.Struct && !isText(ft) {
+			sub := map[string]any{}
+			if err := o.collect(ft, key, sub, names); err != nil {
+				return err
+			}
+			if len(sub) > 0 {
+				table[name] = sub
+			}
+			continue
+		}
+
+		envName := o.envName(key)
+		s, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+		value, err := tomlValue(ft, s)
+		if err != nil {
+			return fmt.Errorf("config: env %s: %w", envName, err)
+		}
+		if value == nil {
+			continue // Unsupported type.
+		}
+		table[name] = value
+		*names = append(*names, envName)
+		o.sources[key] = envSource(envName)
+	}
+	return nil
+}
+
+// tomlValue converts an env var to the value the TOML decoder would pass to
+// UnmarshalTOML for a field of type t, or nil if t is not supported.
+func tomlValue(t reflect.Type, s string) (any, error) {
+	switch {
+	case isText(t) || t == durationType:
+		return s, nil
+	case t.Kind() == reflect.Slice && (isText(t.Elem()) || isScalar(t.Elem())):
+		var values []any
+		for _, item := range splitList(s) {
+			value, err := tomlValue(t.Elem(), item)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return strconv.ParseBool(s) //nolint:wrapcheck
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(s, 10, 64) //nolint:wrapcheck
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(s, 64) //nolint:wrapcheck
+	}
+	return nil, nil
+}
+
+// checkMap returns an error if an env var overrides the map at key or one
+// of its entries, e.g. SERVICES_INDEXER_URL for a map[string]Service at
+// "services". Maps are not supported. Env vars of the sibling keys, e.g.
+// SERVICES_ENABLED for "services_enabled", are not map entries.
+func (o *envOverrider) checkMap(key string, siblings []string) error {
+	name := o.envName(key)
+	for _, env := range os.Environ() {
+		envName, _, _ := strings.Cut(env, "=")
+		if envName != name && !strings.HasPrefix(envName, name+"_") {
+			continue
+		}
+		if slices.ContainsFunc(siblings, func(sibling string) bool {
+			siblingName := o.envName(sibling)
+			return sibling != key && (envName == siblingName || strings.HasPrefix(envName, siblingName+"_"))
+		}) {
+			continue
+		}
+		return fmt.Errorf("config: env %s: overriding map %s is not supported", envName, key)
+	}
+	return nil
+}
+
+func isMap(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Map
+}
+
+func (o *envOverrider) envName(key string) string {
+	return o.prefix + envName(key)
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func splitList(s string) []string {
+	items := strings.Split(s, ",")
+	for i, item := range items {
+		items[i] = strings.TrimSpace(item)
+	}
+	return items
+}
+
+// tomlKey returns the TOML key of an exported struct field, or "" if the
+// field is skipped.
+func tomlKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// envName maps a TOML key to an env var name, e.g. "services.indexer.url"
+// to SERVICES_INDEXER_URL.
+func envName(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+func isText(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// isScalar reports whether t is a basic type with no custom text encoding.
+func isScalar(t reflect.Type) bool {
+	if isText(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func setValue(v reflect.Value, s string) error {
+	if isText(v.Type()) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)) //nolint:forcetypeassert,wrapcheck
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/assert"
+)
+
+func TestApplyEnv(t *testing.T) {
+	var cfg struct {
+		Env      Env           `toml:"env"`
+		Level    slog.Level    `toml:"level"`
+		MinLevel *slog.Level   `toml:"min_level"`
+		Timeout  time.Duration `toml:"timeout"`
+		Origins  []string      `toml:"origins"`
+		Envs     []Env         `toml:"envs"`
+		Ignored  string        `toml:"-"`
+		internal string
+
+		Debug    Debug `toml:"debug"`
+		Services struct {
+			Indexer Service  `toml:"indexer"`
+			API     *Service `toml:"api"`
+		} `toml:"services"`
+	}
+	cfg.Services.Indexer.AccessKey = LiteralSecret("key")
+
+	t.Setenv("APP_ENV", "prod")
+	t.Setenv("APP_LEVEL", "WARN")
+	t.Setenv("APP_MIN_LEVEL", "DEBUG")
+	t.Setenv("APP_TIMEOUT", "1m30s")
+	t.Setenv("APP_ORIGINS", "https://a.example, https://b.example")
+	t.Setenv("APP_ENVS", "dev,stg")
+	t.Setenv("APP_IGNORED", "x")
+	t.Setenv("APP_DEBUG_ENABLED", "true")
+	t.Setenv("APP_DEBUG_BASIC_AUTH_USERNAME", "admin")
+	t.Setenv("APP_SERVICES_INDEXER_URL", "https://indexer.sequence.app")
+	t.Setenv("APP_SERVICES_INDEXER_DEBUG_REQUESTS", "true")
+	t.Setenv("APP_SERVICES_API_URL", "https://api.sequence.app")
+
+	sources, err := ApplyEnv(&cfg, "APP_")
+	assert.NoError(t, err)
+
+	assert.Equal(t, EnvProd, cfg.Env)
+	assert.Equal(t, slog.LevelWarn, cfg.Level)
+	if assert.NotNil(t, cfg.MinLevel) {
+		assert.Equal(t, slog.LevelDebug, *cfg.MinLevel)
+	}
+	assert.Equal(t, 90*time.Second, cfg.Timeout)
+	assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.Origins)
+	assert.Equal(t, []Env{EnvDev, EnvStg}, cfg.Envs)
+	assert.Empty(t, cfg.Ignored)
+	assert.True(t, cfg.Debug.Enabled)
+	assert.Equal(t, "admin", cfg.Debug.BasicAuth.Username)
+	assert.Equal(t, "https://indexer.sequence.app", cfg.Services.Indexer.URL().String())
+	assert.Equal(t, "key", cfg.Services.Indexer.AccessKey.Value())
+	assert.True(t, cfg.Services.Indexer.DebugRequests)
+	assert.Nil(t, cfg.Services.API, "nil structs are not allocated")
+
+	assert.Equal(t, Source("env:APP_SERVICES_INDEXER_URL"), sources.Of("services.indexer.url"))
+	assert.Equal(t, Source("env:APP_DEBUG_BASIC_AUTH_USERNAME"), sources.Of("debug.basic_auth.username"))
+	assert.Equal(t, SourceDefault, sources.Of("services.indexer.access_key"))
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	t.Run("invalid text value", func(t *testing.T) {
+		var cfg struct {
+			Env Env `toml:"env"`
+		}
+		t.Setenv("ENV", "qa")
+		_, err := ApplyEnv(&cfg, "")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "env ENV")
+		}
+	})
+
+	t.Run("service validation runs", func(t *testing.T) {
+		var cfg struct {
+			Indexer Service `toml:"indexer"`
+		}
+		cfg.Indexer.AccessKey = LiteralSecret("key")
+		t.Setenv("INDEXER_JWT_TOKEN", "token")
+		_, err := ApplyEnv(&cfg, "")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "INDEXER_JWT_TOKEN")
+			assert.Contains(t, err.Error(), "mutually exclusive")
+		}
+	})
+
+	t.Run("maps are not supported", func(t *testing.T) {
+		var cfg struct {
+			Services map[string]Service `toml:"services"`
+		}
+		_, err := ApplyEnv(&cfg, "APP_")
+		assert.NoError(t, err, "maps without env vars are ignored")
+
+		t.Setenv("APP_SERVICES_INDEXER_URL", "https://indexer.sequence.app")
+		_, err = ApplyEnv(&cfg, "APP_")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "APP_SERVICES_INDEXER_URL")
+			assert.Contains(t, err.Error(), "map services")
+		}
+	})
+
+	t.Run("sibling of a map", func(t *testing.T) {
+		var cfg struct {
+			Labels        map[string]string `toml:"labels"`
+			LabelsEnabled bool              `toml:"labels_enabled"`
+			Labels2       struct {
+				Name string `toml:"name"`
+			} `toml:"labels_v2"`
+		}
+		t.Setenv("APP_LABELS_ENABLED", "true")
+		t.Setenv("APP_LABELS_V2_NAME", "x")
+		_, err := ApplyEnv(&cfg, "APP_")
+		assert.NoError(t, err)
+		assert.True(t, cfg.LabelsEnabled)
+		assert.Equal(t, "x", cfg.Labels2.Name)
+
+		t.Setenv("APP_LABELS_TEAM", "infra")
+		_, err = ApplyEnv(&cfg, "APP_")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "APP_LABELS_TEAM")
+		}
+	})
+
+	t.Run("service headers are not supported", func(t *testing.T) {
+		var cfg struct {
+			Indexer Service `toml:"indexer"`
+		}
+		t.Setenv("INDEXER_HEADERS_X_API_VERSION", "2")
+		_, err := ApplyEnv(&cfg, "")
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "map indexer.headers")
+		}
+	})
+
+	t.Run("invalid service url", func(t *testing.T) {
+		var cfg struct {
+			Indexer Service `toml:"indexer"`
+		}
+		t.Setenv("INDEXER_URL", "not-a-url")
+		_, err := ApplyEnv(&cfg, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const redacted = "[REDACTED]"
+
+// SecretResolver resolves the reference of a secret without its scheme,
+// e.g. "/run/secrets/jwt" for "file:/run/secrets/jwt".
+type SecretResolver func(ref string) (string, error)
+
+var (
+	secretResolversMu sync.RWMutex
+	secretResolvers   = map[string]SecretResolver{
+		"env":     resolveEnvSecret,
+		"file":    resolveFileSecret,
+		"literal": resolveLiteralSecret,
+	}
+)
+
+// RegisterSecretResolver registers a resolver for secret references with
+// the given scheme, e.g. "vault" for "vault:kv/api#jwt_secret". Register
+// resolvers before loading the config.
+func RegisterSecretResolver(scheme string, resolver SecretResolver) {
+	secretResolversMu.Lock()
+	defer secretResolversMu.Unlock()
+	secretResolvers[scheme] = resolver
+}
+
+// ResolveSecret resolves a secret reference. References are "<scheme>:<ref>"
+// with a registered scheme, by default:
+//
+//	env:JWT_SECRET           // value of the JWT_SECRET env var
+//	file:/run/secrets/jwt    // file contents, without trailing newline
+//	literal:env:not-a-ref    // "env:not-a-ref", as is
+//
+// Any other value is a literal secret, returned as is. A literal secret
+// starting with a registered scheme and ":" is always treated as a
+// reference, so it must be written with the "literal:" prefix.
+func ResolveSecret(ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, ":")
+	if !ok {
+		return ref, nil
+	}
+
+	secretResolversMu.RLock()
+	resolver, ok := secretResolvers[scheme]
+	secretResolversMu.RUnlock()
+	if !ok {
+		return ref, nil
+	}
+
+	value, err := resolver(rest)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s secret: %w", scheme, err)
+	}
+	return value, nil
+}
+
+func resolveEnvSecret(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env %s is not set", name)
+	}
+	return value, nil
+}
+
+func resolveLiteralSecret(value string) (string, error) {
+	return value, nil
+}
+
+func resolveFileSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err //nolint:wrapcheck
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// Secret is a config value resolved from a reference at load time, see
+// ResolveSecret. It never prints its value: String, MarshalText and
+// LogValue return "[REDACTED]". Use Value() to access it.
+//
+//	type Config struct {
+//		JWTSecret config.Secret `toml:"jwt_secret"` // e.g. "file:/run/secrets/jwt"
+//	}
+//
+// Copies of a Secret share its value, so Reload rotates all of them.
+type Secret struct {
+	s *secretState
+}
+
+type secretState struct {
+	ref   string
+	value atomic.Pointer[string]
+}
+
+// NewSecret resolves the secret reference.
+func NewSecret(ref string) (Secret, error) {
+	var s Secret
+	if err := s.UnmarshalText([]byte(ref)); err != nil {
+		return Secret{}, err
+	}
+	return s, nil
+}
+
+// LiteralSecret returns a secret with the given value, never resolved as a
+// reference. Its Ref has the "literal:" prefix if needed, see ResolveSecret.
+func LiteralSecret(value string) Secret {
+	ref := value
+	if isSecretRef(value) {
+		ref = "literal:" + value
+	}
+	state := &secretState{ref: ref}
+	state.value.Store(&value)
+	return Secret{s: state}
+}
+
+// isSecretRef reports whether value starts with a registered scheme.
+func isSecretRef(value string) bool {
+	scheme, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	secretResolversMu.RLock()
+	defer secretResolversMu.RUnlock()
+	_, ok = secretResolvers[scheme]
+	return ok
+}
+
+func (s *Secret) UnmarshalText(text []byte) error {
+	state := &secretState{ref: string(text)}
+	if err := state.resolve(); err != nil {
+		return err
+	}
+	s.s = state
+	return nil
+}
+
+// Reload re-resolves the secret reference, e.g. after the secret file was
+// rotated. On error, the previous value is kept.
+func (s Secret) Reload() error {
+	if s.s == nil {
+		return nil
+	}
+	return s.s.resolve()
+}
+
+func (s *secretState) resolve() error {
+	value, err := ResolveSecret(s.ref)
+	if err != nil {
+		return err
+	}
+	s.value.Store(&value)
+	return nil
+}
+
+// Value returns the resolved secret.
+func (s Secret) Value() string {
+	if s.s == nil {
+		return ""
+	}
+	return *s.s.value.Load()
+}
+
+// Ref returns the secret reference as configured. For literal secrets,
+// it is the secret itself.
+func (s Secret) Ref() string {
+	if s.s == nil {
+		return ""
+	}
+	return s.s.ref
+}
+
+// redactedRef returns the reference of the secret, e.g. "env:JWT_SECRET",
+// or "[REDACTED]" for literal secrets.
+func (s Secret) redactedRef() string {
+	ref := s.Ref()
+	if scheme, _, _ := strings.Cut(ref, ":"); scheme != "literal" && isSecretRef(ref) {
+		return ref
+	}
+	return s.String()
+}
+
+// IsSet reports whether the secret has a non-empty value.
+func (s Secret) IsSet() bool {
+	return s.Value() != ""
+}
+
+func (s Secret) String() string {
+	if !s.IsSet() {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/test-go/testify/assert"
+)
+
+func TestSecret(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jwt")
+	writeFile(t, path, "file-secret\n")
+	t.Setenv("TEST_JWT_SECRET", "env-secret")
+
+	RegisterSecretResolver("test", func(ref string) (string, error) {
+		if ref == "missing" {
+			return "", errors.New("not found")
+		}
+		return "resolved-" + ref, nil
+	})
+
+	var cfg struct {
+		Literal Secret `toml:"literal"`
+		Env     Secret `toml:"env"`
+		File    Secret `toml:"file"`
+		Custom  Secret `toml:"custom"`
+		Unset   Secret `toml:"unset"`
+	}
+	_, err := toml.Decode(fmt.Sprintf(`
+literal = "plain:text"
+env = "env:TEST_JWT_SECRET"
+file = "file:%s"
+custom = "test:jwt"
+`, path), &cfg)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "plain:text", cfg.Literal.Value())
+	assert.Equal(t, "env-secret", cfg.Env.Value())
+	assert.Equal(t, "file-secret", cfg.File.Value())
+	assert.Equal(t, "resolved-jwt", cfg.Custom.Value())
+	assert.Equal(t, "env:TEST_JWT_SECRET", cfg.Env.Ref())
+	assert.False(t, cfg.Unset.IsSet())
+
+	t.Run("never prints value", func(t *testing.T) {
+		assert.Equal(t, "[REDACTED]", cfg.Env.String())
+		assert.Equal(t, "[REDACTED] [REDACTED] [REDACTED]", fmt.Sprintf("%v %s %#v", cfg.Env, cfg.Env, cfg.Env))
+		assert.NotContains(t, fmt.Sprintf("%v %+v %#v", cfg, cfg, cfg), "secret")
+
+		data, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "secret")
+
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("config", slog.Any("jwt", cfg.File))
+		assert.Contains(t, buf.String(), `"jwt":"[REDACTED]"`)
+		assert.NotContains(t, buf.String(), "file-secret")
+
+		assert.Equal(t, "", cfg.Unset.String())
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		copied := cfg.File
+		writeFile(t, path, "rotated")
+		assert.NoError(t, cfg.File.Reload())
+		assert.Equal(t, "rotated", cfg.File.Value())
+		assert.Equal(t, "rotated", copied.Value())
+
+		assert.NoError(t, os.Remove(path))
+		assert.Error(t, cfg.File.Reload())
+		assert.Equal(t, "rotated", cfg.File.Value(), "keeps previous value on error")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, ref := range []string{"env:TEST_MISSING_SECRET", "file:/nonexistent/secret", "test:missing"} {
+			_, err := NewSecret(ref)
+			assert.Error(t, err, ref)
+		}
+	})
+}
+
+func TestServiceSecretReferences(t *testing.T) {
+	t.Setenv("TEST_ACCESS_KEY", "key")
+
+	var cfg struct {
+		Indexer Service `toml:"indexer"`
+	}
+	_, err := toml.Decode(`
+[indexer]
+	url = "https://indexer.sequence.app"
+	access_key = "env:TEST_ACCESS_KEY"
+`, &cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "key", cfg.Indexer.AccessKey.Value())
+	assert.Equal(t, "env:TEST_ACCESS_KEY", cfg.Indexer.AccessKey.Ref())
+	assert.NotContains(t, fmt.Sprintf("%v %+v", cfg, cfg), "key")
+
+	data, err := json.Marshal(struct {
+		BasicAuth BasicAuth `json:"basic_auth"`
+	}{BasicAuth{Username: "admin", Password: LiteralSecret("pass")}})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"basic_auth":{"Username":"admin","Password":"[REDACTED]"}}`, string(data))
+
+	_, err = toml.Decode(`
+[indexer]
+	jwt_secret = "env:TEST_MISSING_SECRET"
+`, &cfg)
+	assert.Error(t, err)
+}
+
+func TestLiteralSecret(t *testing.T) {
+	secret, err := NewSecret("literal:env:NOT_A_REF")
+	assert.NoError(t, err)
+	assert.Equal(t, "env:NOT_A_REF", secret.Value())
+
+	literal := LiteralSecret("env:NOT_A_REF")
+	assert.Equal(t, "env:NOT_A_REF", literal.Value())
+	assert.Equal(t, "literal:env:NOT_A_REF", literal.Ref())
+
+	literal = LiteralSecret("plain")
+	assert.Equal(t, "plain", literal.Ref())
+	assert.NoError(t, literal.Reload())
+	assert.Equal(t, "plain", literal.Value())
+}
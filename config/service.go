@@ -1,65 +1,376 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/url"
+	"time"
 )
 
 type Service struct {
 	Disabled bool    `toml:"disabled"` // Disables the service.
 	url      BaseURL `toml:"url"`      // Service BaseURL. Use URL() to get copy of *url.URL.
 
-	// Mutually exclusive fields.
-	JWTSecret string `toml:"jwt_secret"` // Secret for signing JWT tokens for S2S comms. Mutually exclusive with JWTToken and AccessKey.
-	JWTToken  string `toml:"jwt_token"`  // Custom static JWT token for S2S comms. Mutually exclusive with JWTSecret and AccessKey.
-	AccessKey string `toml:"access_key"` // Access key used as X-Access-Key header. Mutually exclusive with JWTSecret and JWTToken.
+	// Mutually exclusive fields. Values can be secret references, e.g.
+	// "env:INDEXER_ACCESS_KEY", resolved at load time, see ResolveSecret.
+	JWTSecret Secret `toml:"jwt_secret"` // Secret for signing JWT tokens for S2S comms. Mutually exclusive with JWTToken and AccessKey.
+	JWTToken  Secret `toml:"jwt_token"`  // Custom static JWT token for S2S comms. Mutually exclusive with JWTSecret and AccessKey.
+	AccessKey Secret `toml:"access_key"` // Access key used as X-Access-Key header. Mutually exclusive with JWTSecret and JWTToken.
 
 	DebugRequests bool `toml:"debug_requests"` // Enables HTTP request logging in CURL format.
+
+	Timeout   time.Duration     `toml:"timeout"`    // HTTP client timeout, e.g. "10s". No timeout if zero.
+	Retries   int               `toml:"retries"`    // Max retries of idempotent requests on network errors, 429 and 5xx responses.
+	RateLimit float64           `toml:"rate_limit"` // Max requests per second. Unlimited if zero.
+	Headers   map[string]string `toml:"headers"`    // Static headers added to all requests.
+	TLS       ServiceTLS        `toml:"tls"`        // TLS client config.
+	Proxy     string            `toml:"proxy"`      // Proxy URL, e.g. "http://proxy:3128" or "socks5://proxy:1080".
+}
+
+type ServiceTLS struct {
+	CAFile             string `toml:"ca_file"`              // PEM CA bundle to verify the server, in addition to system roots.
+	CertFile           string `toml:"cert_file"`            // PEM client certificate for mTLS. Requires KeyFile.
+	KeyFile            string `toml:"key_file"`             // PEM client key for mTLS. Requires CertFile.
+	InsecureSkipVerify bool   `toml:"insecure_skip_verify"` // Skips server verification. Allowed in local and test envs only.
+}
+
+// ServiceOption configures a Service built by NewService.
+type ServiceOption func(*Service)
+
+// WithJWTSecret sets the secret for signing S2S JWT tokens.
+func WithJWTSecret(secret string) ServiceOption {
+	return func(s *Service) {
+		s.JWTSecret = LiteralSecret(secret)
+	}
+}
+
+// WithJWTToken sets a static S2S JWT token.
+func WithJWTToken(token string) ServiceOption {
+	return func(s *Service) {
+		s.JWTToken = LiteralSecret(token)
+	}
+}
+
+// WithAccessKey sets the access key sent as X-Access-Key header.
+func WithAccessKey(key string) ServiceOption {
+	return func(s *Service) {
+		s.AccessKey = LiteralSecret(key)
+	}
+}
+
+// WithDebugRequests enables HTTP request logging in CURL format.
+func WithDebugRequests() ServiceOption {
+	return func(s *Service) {
+		s.DebugRequests = true
+	}
+}
+
+// NewService builds a validated Service, e.g. for tests and tooling.
+//
+//	svc, err := config.NewService("https://indexer.sequence.app", config.WithAccessKey(key))
+func NewService(rawURL string, opts ...ServiceOption) (Service, error) {
+	u, err := ParseBaseURL(rawURL)
+	if err != nil {
+		return Service{}, fmt.Errorf("failed to unmarshal url: %w", err)
+	}
+	s := Service{url: u}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	if err := s.Validate(); err != nil {
+		return Service{}, err
+	}
+	return s, nil
+}
+
+// fields returns the non-zero fields of s in declaration order, as decoded
+// by UnmarshalTOML. Secret references like "env:JWT_SECRET" stay references.
+// Literal secrets and the url password are redacted, unless raw is set.
+func (s Service) fields(raw bool) []tomlField {
+	var fields []tomlField
+	add := func(key string, value any, set bool) {
+		if set {
+			fields = append(fields, tomlField{key, value})
+		}
+	}
+	u := s.url.String()
+	if raw {
+		text, _ := s.url.MarshalText()
+		u = string(text)
+	}
+	secret := func(secret Secret) string {
+		if raw {
+			return secret.Ref()
+		}
+		return secret.redactedRef()
+	}
+	add("disabled", s.Disabled, s.Disabled)
+	add("url", u, u != "")
+	add("jwt_secret", secret(s.JWTSecret), s.JWTSecret.Ref() != "")
+	add("jwt_token", secret(s.JWTToken), s.JWTToken.Ref() != "")
+	add("access_key", secret(s.AccessKey), s.AccessKey.Ref() != "")
+	add("debug_requests", s.DebugRequests, s.DebugRequests)
+	add("timeout", s.Timeout.String(), s.Timeout != 0)
+	add("retries", s.Retries, s.Retries != 0)
+	add("rate_limit", s.RateLimit, s.RateLimit != 0)
+	add("headers", s.Headers, len(s.Headers) > 0)
+	if tls := s.TLS.fields(); len(tls) > 0 {
+		add("tls", tls, true)
+	}
+	add("proxy", s.Proxy, s.Proxy != "")
+	return fields
+}
+
+func (t ServiceTLS) fields() []tomlField {
+	var fields []tomlField
+	add := func(key string, value any, set bool) {
+		if set {
+			fields = append(fields, tomlField{key, value})
+		}
+	}
+	add("ca_file", t.CAFile, t.CAFile != "")
+	add("cert_file", t.CertFile, t.CertFile != "")
+	add("key_file", t.KeyFile, t.KeyFile != "")
+	add("insecure_skip_verify", t.InsecureSkipVerify, t.InsecureSkipVerify)
+	return fields
+}
+
+// MarshalTOML encodes the service as a TOML inline table, including the
+// unexported url. The encoding is lossless, i.e. it includes literal
+// secrets and the url password, so it can be written back as config.
+func (s Service) MarshalTOML() ([]byte, error) {
+	return marshalInlineTable(s.fields(true))
+}
+
+// MarshalJSON encodes the service as a JSON object with the TOML keys.
+// Literal secrets and the url password are redacted, so services can be
+// logged or served safely. Use RawJSON for a lossless encoding.
+func (s Service) MarshalJSON() ([]byte, error) {
+	return marshalJSONObject(s.fields(false))
+}
+
+// RawJSON encodes the service like MarshalJSON, but losslessly, including
+// literal secrets and the url password, e.g. for config tooling.
+func (s Service) RawJSON() ([]byte, error) {
+	return marshalJSONObject(s.fields(true))
+}
+
+// LogValue logs the service fields like MarshalJSON, with literal secrets
+// and the url password redacted.
+func (s Service) LogValue() slog.Value {
+	return slog.GroupValue(logAttrs(s.fields(false))...)
+}
+
+func logAttrs(fields []tomlField) []slog.Attr {
+	attrs := make([]slog.Attr, len(fields))
+	for i, field := range fields {
+		if table, ok := field.value.([]tomlField); ok {
+			attrs[i] = slog.Attr{Key: field.key, Value: slog.GroupValue(logAttrs(table)...)}
+			continue
+		}
+		attrs[i] = slog.Any(field.key, field.value)
+	}
+	return attrs
+}
+
+func marshalJSONObject(fields []tomlField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		key, _ := json.Marshal(field.key)
+		var value []byte
+		var err error
+		if table, ok := field.value.([]tomlField); ok {
+			value, err = marshalJSONObject(table)
+		} else {
+			value, err = json.Marshal(field.value)
+		}
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		buf.Write(key)
+		buf.WriteString(":")
+		buf.Write(value)
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON decodes a JSON object with the TOML keys, with the same
+// validation as UnmarshalTOML.
+func (s *Service) UnmarshalJSON(data []byte) error {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err //nolint:wrapcheck
+	}
+	return s.UnmarshalTOML(m)
 }
 
 // UnmarshalTOML implements custom TOML unmarshaling with validation.
+// Disabled services keep all decoded fields, but aren't validated.
 func (s *Service) UnmarshalTOML(v any) error {
 	m, ok := v.(map[string]any)
 	if !ok {
 		return fmt.Errorf("expected map[string]any, got %T", v)
 	}
 
+	err := s.decode(m)
+	if disabled, _ := m["disabled"].(bool); disabled {
+		return nil // Disabled. Skip validation of other fields.
+	}
+	if err != nil {
+		return err
+	}
+	return s.validate()
+}
+
+// decode decodes all fields of m, returning the joined errors.
+func (s *Service) decode(m map[string]any) error {
+	var errs []error
+
 	if val, ok := m["disabled"].(bool); ok {
 		s.Disabled = val
-		if val {
-			return nil // Disabled. Stop validating other fields.
-		}
 	}
-
 	if val, ok := m["url"].(string); ok {
 		if err := s.url.UnmarshalText([]byte(val)); err != nil {
-			return fmt.Errorf("failed to unmarshal url: %w", err)
+			errs = append(errs, fmt.Errorf("failed to unmarshal url: %w", err))
 		}
 	}
-	if val, ok := m["jwt_secret"].(string); ok {
-		s.JWTSecret = val
-	}
-	if val, ok := m["jwt_token"].(string); ok {
-		s.JWTToken = val
-	}
-	if val, ok := m["access_key"].(string); ok {
-		s.AccessKey = val
+	for key, field := range map[string]*Secret{
+		"jwt_secret": &s.JWTSecret,
+		"jwt_token":  &s.JWTToken,
+		"access_key": &s.AccessKey,
+	} {
+		if val, ok := m[key].(string); ok {
+			if err := field.UnmarshalText([]byte(val)); err != nil {
+				errs = append(errs, fmt.Errorf("failed to resolve %s: %w", key, err))
+			}
+		}
 	}
 	if val, ok := m["debug_requests"].(bool); ok {
 		s.DebugRequests = val
 	}
+	if val, ok := m["timeout"].(string); ok {
+		if timeout, err := time.ParseDuration(val); err != nil {
+			errs = append(errs, fmt.Errorf("failed to unmarshal timeout: %w", err))
+		} else {
+			s.Timeout = timeout
+		}
+	}
+	if val, ok := m["retries"]; ok {
+		if retries, ok := tomlNumber(val); !ok || retries != float64(int(retries)) {
+			errs = append(errs, fmt.Errorf("failed to unmarshal retries: expected integer, got %v", val))
+		} else {
+			s.Retries = int(retries)
+		}
+	}
+	if val, ok := m["rate_limit"]; ok {
+		if rateLimit, ok := tomlNumber(val); !ok {
+			errs = append(errs, fmt.Errorf("failed to unmarshal rate_limit: expected number, got %v", val))
+		} else {
+			s.RateLimit = rateLimit
+		}
+	}
+	if val, ok := m["headers"].(map[string]any); ok {
+		s.Headers = make(map[string]string, len(val))
+		for key, value := range val {
+			str, ok := value.(string)
+			if !ok {
+				errs = append(errs, fmt.Errorf("failed to unmarshal headers.%s: expected string, got %T", key, value))
+				continue
+			}
+			s.Headers[key] = str
+		}
+	}
+	if val, ok := m["tls"].(map[string]any); ok {
+		for key, field := range map[string]*string{
+			"ca_file":   &s.TLS.CAFile,
+			"cert_file": &s.TLS.CertFile,
+			"key_file":  &s.TLS.KeyFile,
+		} {
+			if val, ok := val[key].(string); ok {
+				*field = val
+			}
+		}
+		if val, ok := val["insecure_skip_verify"].(bool); ok {
+			s.TLS.InsecureSkipVerify = val
+		}
+	}
+	if val, ok := m["proxy"].(string); ok {
+		s.Proxy = val
+	}
 
-	return s.Validate() //nolint:wrapcheck
+	return errors.Join(errs...)
 }
 
+// tomlNumber returns a TOML integer or float, or a JSON number, as float64.
+func tomlNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// Validate validates the service, unless it's disabled.
 func (s *Service) Validate() error {
+	if s.Disabled {
+		return nil
+	}
+	return s.validate()
+}
+
+func (s *Service) validate() error {
+	var errs []error
+
 	// Validate mutually exclusive auth fields
 	switch {
 	case
-		s.JWTSecret != "" && s.JWTToken != "",
-		s.JWTSecret != "" && s.AccessKey != "",
-		s.JWTToken != "" && s.AccessKey != "":
-		return fmt.Errorf("mutually exclusive auth fields: only one of jwt_secret, jwt_token, or access_key can be set")
+		s.JWTSecret.IsSet() && s.JWTToken.IsSet(),
+		s.JWTSecret.IsSet() && s.AccessKey.IsSet(),
+		s.JWTToken.IsSet() && s.AccessKey.IsSet():
+		errs = append(errs, fmt.Errorf("mutually exclusive auth fields: only one of jwt_secret, jwt_token, or access_key can be set"))
+	}
+
+	if s.Timeout != 0 {
+		errs = append(errs, InRange("timeout", s.Timeout, time.Millisecond, time.Hour))
+	}
+	errs = append(errs,
+		InRange("retries", s.Retries, 0, 10),
+		InRange("rate_limit", s.RateLimit, 0, math.MaxFloat64),
+	)
+	if (s.TLS.CertFile == "") != (s.TLS.KeyFile == "") {
+		errs = append(errs, &FieldError{Path: "tls", Err: errors.New("cert_file and key_file must be set together")})
+	}
+	if s.Proxy != "" {
+		proxy, err := url.Parse(s.Proxy)
+		if err != nil || proxy.Host == "" {
+			errs = append(errs, &FieldError{Path: "proxy", Err: fmt.Errorf("invalid proxy url %q", s.Proxy)})
+		} else {
+			errs = append(errs, URLScheme("proxy", proxy, "http", "https", "socks5"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ValidateEnv only allows tls.insecure_skip_verify in local and test tier
+// envs.
+func (s *Service) ValidateEnv(env Env) error {
+	if s.Disabled {
+		return nil
+	}
+	if tier := env.Tier(); s.TLS.InsecureSkipVerify && tier != TierLocal && tier != TierTest {
+		return &FieldError{Path: "tls.insecure_skip_verify", Err: fmt.Errorf("not allowed in %s", env)}
 	}
 	return nil
 }
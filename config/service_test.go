@@ -1,7 +1,11 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/test-go/testify/assert"
@@ -129,3 +133,166 @@ func TestServiceTOMLFields(t *testing.T) {
 		})
 	}
 }
+
+func TestNewService(t *testing.T) {
+	svc, err := NewService("https://indexer.sequence.app", WithAccessKey("key"), WithDebugRequests())
+	assert.NoError(t, err)
+	assert.Equal(t, "https://indexer.sequence.app", svc.URL().String())
+	assert.Equal(t, "key", svc.AccessKey.Value())
+	assert.True(t, svc.DebugRequests)
+
+	_, err = NewService("indexer.sequence.app")
+	assert.Error(t, err)
+
+	_, err = NewService("https://indexer.sequence.app", WithJWTSecret("secret"), WithJWTToken("token"))
+	assert.Error(t, err)
+}
+
+type roundTripConfig struct {
+	Services struct {
+		Metadata Service  `toml:"metadata" json:"metadata"`
+		Indexer  Service  `toml:"indexer" json:"indexer"`
+		API      *Service `toml:"api" json:"api"`
+		Disabled Service  `toml:"disabled" json:"disabled"`
+	} `toml:"services" json:"services"`
+}
+
+// assertServicesEqual compares the decoded secret values, and the
+// remaining fields with secrets cleared.
+func assertServicesEqual(t *testing.T, want, got roundTripConfig) {
+	t.Helper()
+	strip := func(s Service) (Service, [3]string) {
+		secrets := [3]string{s.JWTSecret.Value(), s.JWTToken.Value(), s.AccessKey.Value()}
+		s.JWTSecret, s.JWTToken, s.AccessKey = Secret{}, Secret{}, Secret{}
+		return s, secrets
+	}
+	pairs := [][2]*Service{
+		{&want.Services.Metadata, &got.Services.Metadata},
+		{&want.Services.Indexer, &got.Services.Indexer},
+		{want.Services.API, got.Services.API},
+		{&want.Services.Disabled, &got.Services.Disabled},
+	}
+	for _, pair := range pairs {
+		if !assert.NotNil(t, pair[1]) {
+			continue
+		}
+		wantSvc, wantSecrets := strip(*pair[0])
+		gotSvc, gotSecrets := strip(*pair[1])
+		assert.Equal(t, wantSvc, gotSvc)
+		assert.Equal(t, wantSecrets, gotSecrets)
+	}
+}
+
+func TestServiceRoundTrip(t *testing.T) {
+	type Config = roundTripConfig
+
+	var cfg Config
+	var err error
+	cfg.Services.Metadata, err = NewService("http://localhost:4242/rpc", WithJWTSecret(`se"cr\et`))
+	assert.NoError(t, err)
+	cfg.Services.Metadata.Timeout = 1500 * time.Millisecond
+	cfg.Services.Metadata.Retries = 2
+	cfg.Services.Metadata.RateLimit = 0.5
+	cfg.Services.Metadata.Headers = map[string]string{"X-Client": "api", "Origin": "https://sequence.app"}
+	cfg.Services.Metadata.TLS = ServiceTLS{CAFile: "/etc/ssl/ca.pem", InsecureSkipVerify: true}
+	cfg.Services.Metadata.Proxy = "http://proxy:3128"
+	cfg.Services.Indexer, err = NewService("https://indexer.sequence.app", WithAccessKey("key"), WithDebugRequests())
+	assert.NoError(t, err)
+	api, err := NewService("https://api.sequence.app", WithJWTToken("token\n"))
+	assert.NoError(t, err)
+	cfg.Services.API = &api
+	cfg.Services.Disabled, err = NewService("https://disabled.sequence.app", WithAccessKey("disabled-key"))
+	assert.NoError(t, err)
+	cfg.Services.Disabled.Disabled = true
+	cfg.Services.Disabled.Timeout = time.Second
+
+	t.Run("toml", func(t *testing.T) {
+		var buf bytes.Buffer
+		assert.NoError(t, toml.NewEncoder(&buf).Encode(cfg))
+		assert.Contains(t, buf.String(), `indexer = { url = "https://indexer.sequence.app", access_key = "key", debug_requests = true }`)
+		assert.Contains(t, buf.String(), `disabled = { disabled = true, url = "https://disabled.sequence.app", access_key = "disabled-key", timeout = "1s" }`)
+
+		var decoded Config
+		_, err := toml.Decode(buf.String(), &decoded)
+		assert.NoError(t, err)
+		assertServicesEqual(t, cfg, decoded)
+
+		// Secrets hold pointers, so compare the encoded configs.
+		var again bytes.Buffer
+		assert.NoError(t, toml.NewEncoder(&again).Encode(decoded))
+		assert.Equal(t, buf.String(), again.String())
+	})
+
+	t.Run("json is redacted", func(t *testing.T) {
+		data, err := json.Marshal(cfg)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `"indexer":{"url":"https://indexer.sequence.app","access_key":"[REDACTED]","debug_requests":true}`)
+		for _, secret := range []string{`"key"`, `se\"cr`, `token\n`} {
+			assert.NotContains(t, string(data), secret)
+		}
+
+		var buf bytes.Buffer
+		slog.New(slog.NewJSONHandler(&buf, nil)).Info("service", "indexer", cfg.Services.Indexer)
+		assert.Contains(t, buf.String(), `"indexer":{"url":"https://indexer.sequence.app","access_key":"[REDACTED]","debug_requests":true}`)
+	})
+
+	t.Run("raw json", func(t *testing.T) {
+		var decoded Config
+		for _, pair := range [][2]*Service{
+			{&cfg.Services.Metadata, &decoded.Services.Metadata},
+			{&cfg.Services.Indexer, &decoded.Services.Indexer},
+			{&cfg.Services.Disabled, &decoded.Services.Disabled},
+		} {
+			data, err := pair[0].RawJSON()
+			assert.NoError(t, err)
+			assert.NoError(t, json.Unmarshal(data, pair[1]))
+
+			again, err := pair[1].RawJSON()
+			assert.NoError(t, err)
+			assert.Equal(t, string(data), string(again))
+		}
+		data, err := cfg.Services.Indexer.RawJSON()
+		assert.NoError(t, err)
+		assert.Equal(t, `{"url":"https://indexer.sequence.app","access_key":"key","debug_requests":true}`, string(data))
+
+		decoded.Services.API = cfg.Services.API
+		assertServicesEqual(t, cfg, decoded)
+	})
+
+	t.Run("secret references", func(t *testing.T) {
+		t.Setenv("TEST_INDEXER_KEY", "resolved-key")
+		var svc Service
+		_, err := toml.Decode(`url = "https://indexer.sequence.app"
+access_key = "env:TEST_INDEXER_KEY"`, &svc)
+		assert.NoError(t, err)
+		assert.Equal(t, "resolved-key", svc.AccessKey.Value())
+
+		data, err := svc.MarshalTOML()
+		assert.NoError(t, err)
+		assert.Equal(t, `{ url = "https://indexer.sequence.app", access_key = "env:TEST_INDEXER_KEY" }`, string(data))
+
+		data, err = json.Marshal(svc)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "resolved-key")
+		assert.Contains(t, string(data), `"access_key":"env:TEST_INDEXER_KEY"`)
+
+		literal, err := NewService("https://indexer.sequence.app", WithAccessKey("env:NOT_A_REF"))
+		assert.NoError(t, err)
+		data, err = literal.MarshalTOML()
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), `access_key = "literal:env:NOT_A_REF"`)
+	})
+
+	t.Run("disabled skips validation", func(t *testing.T) {
+		var svc Service
+		assert.NoError(t, json.Unmarshal([]byte(`{"disabled":true,"jwt_token":"t","access_key":"k","retries":100}`), &svc))
+		assert.Equal(t, 100, svc.Retries)
+		assert.Equal(t, "k", svc.AccessKey.Value())
+		assert.NoError(t, svc.Validate())
+	})
+
+	t.Run("json validates", func(t *testing.T) {
+		var svc Service
+		assert.Error(t, json.Unmarshal([]byte(`{"url":"https://a.example","jwt_token":"t","access_key":"k"}`), &svc))
+	})
+}
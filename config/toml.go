@@ -0,0 +1,131 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tomlField is a key/value pair of an ordered TOML table.
+type tomlField struct {
+	key   string
+	value any
+}
+
+// marshalInlineTable encodes fields as a TOML inline table, e.g.
+// { url = "https://example.com", debug_requests = true }. Supported values
+// are strings, bools, ints, floats, []string, map[string]string and nested
+// []tomlField tables.
+func marshalInlineTable(fields []tomlField) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("{")
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(" ")
+		buf.WriteString(tomlKeyString(field.key))
+		buf.WriteString(" = ")
+		if err := writeTOMLValue(&buf, field.value); err != nil {
+			return nil, fmt.Errorf("%s: %w", field.key, err)
+		}
+	}
+	if len(fields) > 0 {
+		buf.WriteString(" ")
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+func writeTOMLValue(buf *bytes.Buffer, value any) error {
+	switch v := value.(type) {
+	case string:
+		buf.WriteString(tomlQuote(v))
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		buf.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case []string:
+		buf.WriteString("[")
+		for i, s := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(tomlQuote(s))
+		}
+		buf.WriteString("]")
+	case map[string]string:
+		fields := make([]tomlField, 0, len(v))
+		for _, key := range sortedKeys(v) {
+			fields = append(fields, tomlField{key, v[key]})
+		}
+		return writeTOMLValue(buf, fields)
+	case []tomlField:
+		table, err := marshalInlineTable(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(table)
+	default:
+		return fmt.Errorf("unsupported type %T", value)
+	}
+	return nil
+}
+
+// tomlKeyString returns key as a bare key if possible, or quoted.
+func tomlKeyString(key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool {
+		return !(r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) || r == '_' || r == '-')
+	}) == -1 {
+		return key
+	}
+	return tomlQuote(key)
+}
+
+// tomlQuote returns s as a TOML basic string.
+func tomlQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\b':
+			b.WriteString(`\b`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\f':
+			b.WriteString(`\f`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
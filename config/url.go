@@ -1,12 +1,122 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
+	"path"
+	"slices"
+	"strings"
 )
 
+// BaseURL is a validated service base URL. Besides "scheme://host/path"
+// URLs, it accepts "unix:///path/to/socket" for HTTP over a unix socket:
+// URL() then returns "http://unix/" and Service.Client() dials the socket.
 type BaseURL struct {
-	u url.URL
+	u      url.URL
+	socket string // Unix socket path.
+}
+
+// URLOption is an extra validation rule for ParseBaseURL and ValidURL.
+type URLOption func(*urlOptions)
+
+type urlOptions struct {
+	schemes    []string
+	noQuery    bool
+	noFragment bool
+	noUserinfo bool
+}
+
+// AllowSchemes only allows the given schemes, e.g. "https" or "unix".
+func AllowSchemes(schemes ...string) URLOption {
+	return func(o *urlOptions) {
+		o.schemes = schemes
+	}
+}
+
+// NoQuery forbids a query string, e.g. "?key=value".
+func NoQuery() URLOption {
+	return func(o *urlOptions) {
+		o.noQuery = true
+	}
+}
+
+// NoFragment forbids a fragment, e.g. "#section".
+func NoFragment() URLOption {
+	return func(o *urlOptions) {
+		o.noFragment = true
+	}
+}
+
+// NoUserinfo forbids credentials in the URL, e.g. "user:pass@".
+func NoUserinfo() URLOption {
+	return func(o *urlOptions) {
+		o.noUserinfo = true
+	}
+}
+
+// ParseBaseURL parses and validates a base URL, see UnmarshalText.
+func ParseBaseURL(rawURL string, opts ...URLOption) (BaseURL, error) {
+	var u BaseURL
+	if err := u.UnmarshalText([]byte(rawURL)); err != nil {
+		return BaseURL{}, err
+	}
+	if err := u.check(opts...); err != nil {
+		return BaseURL{}, err
+	}
+	return u, nil
+}
+
+// ValidURL fails if u is set and doesn't pass the validation options.
+//
+//	func (c *Config) Validate() error {
+//		return config.ValidURL("callback_url", c.CallbackURL, config.AllowSchemes("https"), config.NoUserinfo())
+//	}
+func ValidURL(key string, u BaseURL, opts ...URLOption) error {
+	if u.URL() == nil {
+		return nil
+	}
+	if err := u.check(opts...); err != nil {
+		return &FieldError{Path: key, Err: err}
+	}
+	return nil
+}
+
+func (u *BaseURL) check(opts ...URLOption) error {
+	var o urlOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if len(o.schemes) > 0 && !slices.ContainsFunc(o.schemes, func(scheme string) bool {
+		return strings.EqualFold(scheme, u.Scheme())
+	}) {
+		return fmt.Errorf("scheme %q not allowed, expected one of %v", u.Scheme(), o.schemes)
+	}
+	if o.noQuery && u.u.RawQuery != "" {
+		return errors.New("query not allowed")
+	}
+	if o.noFragment && u.u.Fragment != "" {
+		return errors.New("fragment not allowed")
+	}
+	if o.noUserinfo && u.u.User != nil {
+		return errors.New("userinfo not allowed")
+	}
+	return nil
+}
+
+// MarshalText returns the URL string, or an empty string if unset. It is
+// lossless for config round trips, so it includes the password. Use String
+// or LogValue, which redact it, for logs and other output.
+func (u BaseURL) MarshalText() ([]byte, error) {
+	switch {
+	case u.socket != "":
+		return []byte("unix://" + u.socket), nil
+	case u.u.Host == "":
+		return []byte{}, nil
+	}
+	return []byte(u.u.String()), nil
 }
 
 func (u *BaseURL) UnmarshalText(text []byte) error {
@@ -15,10 +125,19 @@ func (u *BaseURL) UnmarshalText(text []byte) error {
 	if err != nil {
 		return err //nolint:wrapcheck
 	}
+	if parsed.Scheme == "unix" {
+		if parsed.Host != "" || parsed.Path == "" {
+			return fmt.Errorf("unix socket path is required, e.g. unix:///var/run/api.sock: %q", str)
+		}
+		u.u = url.URL{Scheme: "http", Host: "unix", Path: "/"}
+		u.socket = parsed.Path
+		return nil
+	}
 	if parsed.Host == "" {
 		return fmt.Errorf("host is required: %q", str)
 	}
 	u.u = *parsed
+	u.socket = ""
 	return nil
 }
 
@@ -30,3 +149,107 @@ func (u *BaseURL) URL() *url.URL {
 	*copy = u.u
 	return copy
 }
+
+// Scheme returns the configured scheme, "unix" for unix sockets.
+func (u *BaseURL) Scheme() string {
+	if u.socket != "" {
+		return "unix"
+	}
+	return u.u.Scheme
+}
+
+// SocketPath returns the unix socket path, or "" for network URLs.
+func (u *BaseURL) SocketPath() string {
+	return u.socket
+}
+
+// String returns the URL with the password and sensitive query parameters
+// redacted, see IsSensitiveKey.
+func (u BaseURL) String() string {
+	if u.socket != "" {
+		return "unix://" + u.socket
+	}
+	if u.u.Host == "" {
+		return ""
+	}
+	return redactedURL(&u.u)
+}
+
+// LogValue returns the URL with the password and sensitive query
+// parameters redacted.
+func (u BaseURL) LogValue() slog.Value {
+	return slog.StringValue(u.String())
+}
+
+// JoinPath returns a copy of the URL with elem joined to its path, like
+// url.JoinPath, or nil if unset. Use ResolvePath for untrusted elements.
+//
+//	u.JoinPath("rpc", "Indexer", "GetBalance") // https://indexer.sequence.app/rpc/Indexer/GetBalance
+func (u *BaseURL) JoinPath(elem ...string) *url.URL {
+	base := u.URL()
+	if base == nil {
+		return nil
+	}
+	return base.JoinPath(elem...)
+}
+
+// ResolvePath is like JoinPath for untrusted elements. It fails if the URL
+// is unset, if an element has a ".." segment, also percent-encoded like
+// "%2e%2e", or if the resulting path escapes the base path.
+func (u *BaseURL) ResolvePath(elem ...string) (*url.URL, error) {
+	base := u.URL()
+	if base == nil {
+		return nil, errors.New("base url is not set")
+	}
+
+	for _, e := range elem {
+		unescaped, err := url.PathUnescape(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path %q: %w", e, err)
+		}
+		if slices.Contains(strings.Split(unescaped, "/"), "..") {
+			return nil, fmt.Errorf("path %q escapes base path %q", e, base.Path)
+		}
+	}
+
+	joined := base.JoinPath(elem...)
+	basePath := strings.TrimSuffix(path.Clean("/"+base.Path), "/")
+	if cleaned := path.Clean("/" + joined.Path); cleaned != basePath && !strings.HasPrefix(cleaned, basePath+"/") {
+		return nil, fmt.Errorf("path %q escapes base path %q", path.Join(elem...), base.Path)
+	}
+	return joined, nil
+}
+
+// redactedURL returns u with the password and the values of sensitive query
+// parameters replaced by "xxxxx", like url.URL.Redacted.
+func redactedURL(u *url.URL) string {
+	if u.RawQuery == "" {
+		return u.Redacted()
+	}
+	params := strings.Split(u.RawQuery, "&")
+	for i, param := range params {
+		key, _, ok := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); ok && err == nil && IsSensitiveKey(name) {
+			params[i] = key + "=xxxxx"
+		}
+	}
+	redacted := *u
+	redacted.RawQuery = strings.Join(params, "&")
+	return redacted.Redacted()
+}
+
+// redactURL redacts s like redactedURL, if s is an absolute URL. Other
+// values are returned as is.
+func redactURL(s string) string {
+	if !strings.Contains(s, "://") {
+		return s
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return s
+	}
+	if redacted := redactedURL(u); redacted != u.String() {
+		return redacted
+	}
+	return s
+}
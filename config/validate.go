@@ -0,0 +1,205 @@
+package config
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// Validator is implemented by config types validating their own fields.
+// Return errors from the checks below (Required, InRange, ...), joined with
+// errors.Join, to report all failures with their TOML paths.
+//
+//	func (c *Config) Validate() error {
+//		return errors.Join(
+//			config.Required("name", c.Name),
+//			config.InRange("port", c.Port, 1, 65535),
+//		)
+//	}
+type Validator interface {
+	Validate() error
+}
+
+// EnvValidator is implemented by config types with env-specific rules.
+//
+//	func (c *Auth) ValidateEnv(env config.Env) error {
+//		return config.RequiredIn(env, "jwt_secret", c.JWTSecret, config.EnvProd)
+//	}
+type EnvValidator interface {
+	ValidateEnv(env Env) error
+}
+
+// FieldError is a validation error of the config field at Path, e.g.
+// "services.indexer.url". Path is empty for errors of the root value.
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Validate walks the config tree of cfg, calling Validate and ValidateEnv
+// on all values implementing Validator or EnvValidator, and returns all
+// failures joined, each as a *FieldError with the TOML path of the value:
+//
+//	services.indexer: mutually exclusive auth fields: ...
+//	services.indexer.url: scheme "http" not allowed, expected one of [https]
+//
+// Load calls Validate with Options.Env after loading.
+func Validate(cfg any, env Env) error {
+	v := &validator{env: env}
+	v.walk(reflect.ValueOf(cfg), "")
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	env  Env
+	errs []error
+}
+
+func (v *validator) walk(rv reflect.Value, path string) {
+	if !rv.IsValid() {
+		return
+	}
+	if rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return
+		}
+		v.walk(rv.Elem(), path)
+		return
+	}
+
+	v.validate(rv, path)
+
+	switch rv.Kind() {
+	case reflect.Struct:
+		t := rv.Type()
+		for i := range t.NumField() {
+			if key := tomlKey(t.Field(i)); key != "" {
+				v.walk(rv.Field(i), joinKey(path, key))
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range rv.Len() {
+			v.walk(rv.Index(i), fmt.Sprintf("%s[%d]", path, i))
+		}
+	case reflect.Map:
+		keys := rv.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return cmp.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+		for _, key := range keys {
+			v.walk(rv.MapIndex(key), joinKey(path, fmt.Sprint(key)))
+		}
+	}
+}
+
+// validate calls the validators implemented by rv.
+func (v *validator) validate(rv reflect.Value, path string) {
+	if !rv.CanAddr() {
+		// Pointer receivers need an addressable copy, e.g. for map values.
+		ptr := reflect.New(rv.Type())
+		ptr.Elem().Set(rv)
+		rv = ptr.Elem()
+	}
+	value := rv.Addr().Interface()
+
+	if validator, ok := value.(Validator); ok {
+		v.add(path, validator.Validate())
+	}
+	if validator, ok := value.(EnvValidator); ok {
+		v.add(path, validator.ValidateEnv(v.env))
+	}
+}
+
+// add flattens joined errors and prefixes field errors with path.
+func (v *validator) add(path string, err error) {
+	if err == nil {
+		return
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, err := range joined.Unwrap() {
+			v.add(path, err)
+		}
+		return
+	}
+	if fieldErr, ok := err.(*FieldError); ok { //nolint:errorlint
+		v.errs = append(v.errs, &FieldError{Path: joinKey(path, fieldErr.Path), Err: fieldErr.Err})
+		return
+	}
+	v.errs = append(v.errs, &FieldError{Path: path, Err: err})
+}
+
+// Required fails if value is the zero value of its type.
+func Required(key string, value any) error {
+	if isZero(value) {
+		return &FieldError{Path: key, Err: errors.New("required")}
+	}
+	return nil
+}
+
+// RequiredIn fails if value is the zero value of its type in one of envs,
+// e.g. jwt_secret required in prod.
+func RequiredIn(env Env, key string, value any, envs ...Env) error {
+	if env.Is(envs...) && isZero(value) {
+		return &FieldError{Path: key, Err: fmt.Errorf("required in %s", env)}
+	}
+	return nil
+}
+
+// InRange fails if value is not within [low, high].
+func InRange[T cmp.Ordered](key string, value, low, high T) error {
+	if value < low || value > high {
+		return &FieldError{Path: key, Err: fmt.Errorf("%v out of range [%v, %v]", value, low, high)}
+	}
+	return nil
+}
+
+// OneOf fails if value is not one of allowed.
+func OneOf[T comparable](key string, value T, allowed ...T) error {
+	if !slices.Contains(allowed, value) {
+		return &FieldError{Path: key, Err: fmt.Errorf("%v not allowed, expected one of %v", value, allowed)}
+	}
+	return nil
+}
+
+// URLScheme fails if u is set and its scheme is not one of schemes.
+func URLScheme(key string, u *url.URL, schemes ...string) error {
+	if u == nil {
+		return nil
+	}
+	if !slices.ContainsFunc(schemes, func(scheme string) bool {
+		return strings.EqualFold(scheme, u.Scheme)
+	}) {
+		return &FieldError{Path: key, Err: fmt.Errorf("scheme %q not allowed, expected one of %v", u.Scheme, schemes)}
+	}
+	return nil
+}
+
+func isZero(value any) bool {
+	if value == nil {
+		return true
+	}
+	switch value := value.(type) {
+	case Secret:
+		return !value.IsSet()
+	case BaseURL:
+		return value.URL() == nil
+	case *url.URL:
+		return value == nil || *value == url.URL{}
+	}
+	return reflect.ValueOf(value).IsZero()
+}
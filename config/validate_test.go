@@ -0,0 +1,117 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+)
+
+type testAuth struct {
+	JWTSecret Secret `toml:"jwt_secret"`
+}
+
+func (a *testAuth) ValidateEnv(env Env) error {
+	return RequiredIn(env, "jwt_secret", a.JWTSecret, EnvProd)
+}
+
+type testServer struct {
+	Name    string   `toml:"name"`
+	Port    int      `toml:"port"`
+	Mode    string   `toml:"mode"`
+	Auth    testAuth `toml:"auth"`
+	Backend BaseURL  `toml:"backend"`
+
+	Services map[string]Service `toml:"services"`
+	Replicas []testReplica      `toml:"replicas"`
+}
+
+func (s *testServer) Validate() error {
+	return errors.Join(
+		Required("name", s.Name),
+		InRange("port", s.Port, 1, 65535),
+		OneOf("mode", s.Mode, "http", "grpc"),
+		URLScheme("backend", s.Backend.URL(), "https"),
+	)
+}
+
+type testReplica struct {
+	Weight float64 `toml:"weight"`
+}
+
+func (r testReplica) Validate() error {
+	return InRange("weight", r.Weight, 0, 1)
+}
+
+func TestValidate(t *testing.T) {
+	var backend BaseURL
+	assert.NoError(t, backend.UnmarshalText([]byte("http://backend")))
+
+	cfg := testServer{
+		Port:    70000,
+		Mode:    "tcp",
+		Backend: backend,
+		Services: map[string]Service{
+			"indexer": {JWTToken: LiteralSecret("token"), AccessKey: LiteralSecret("key")},
+			"api":     {},
+		},
+		Replicas: []testReplica{{Weight: 0.5}, {Weight: 2}},
+	}
+
+	err := Validate(&cfg, EnvProd)
+	if !assert.Error(t, err) {
+		return
+	}
+	assert.Equal(t, `name: required
+port: 70000 out of range [1, 65535]
+mode: tcp not allowed, expected one of [http grpc]
+backend: scheme "http" not allowed, expected one of [https]
+auth.jwt_secret: required in prod
+services.indexer: mutually exclusive auth fields: only one of jwt_secret, jwt_token, or access_key can be set
+replicas[1].weight: 2 out of range [0, 1]`, err.Error())
+
+	var fieldErr *FieldError
+	assert.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "name", fieldErr.Path)
+
+	t.Run("env-conditional", func(t *testing.T) {
+		err := Validate(&cfg, EnvDev)
+		assert.NotContains(t, err.Error(), "auth.jwt_secret")
+	})
+
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, backend.UnmarshalText([]byte("https://backend")))
+		secret, err := NewSecret("s3cr3t")
+		assert.NoError(t, err)
+
+		cfg := testServer{Name: "api", Port: 8080, Mode: "http", Backend: backend, Auth: testAuth{JWTSecret: secret}}
+		assert.NoError(t, Validate(&cfg, EnvProd))
+	})
+}
+
+func TestLoadValidates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeFile(t, path, `
+name = "api"
+port = 0
+mode = "http"
+`)
+
+	var cfg testServer
+	_, err := Load(&cfg, Options{Path: path, Env: EnvDev, DisableEnv: true})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "port: 0 out of range")
+	}
+}
+
+func TestIsZero(t *testing.T) {
+	assert.True(t, isZero(nil))
+	assert.True(t, isZero(""))
+	assert.True(t, isZero(Secret{}))
+	assert.True(t, isZero(BaseURL{}))
+	assert.True(t, isZero((*url.URL)(nil)))
+	assert.False(t, isZero(0.1))
+	assert.False(t, isZero(EnvDev))
+}
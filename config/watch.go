@@ -0,0 +1,342 @@
+package config
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// WatchOptions configures Watch.
+type WatchOptions struct {
+	Options
+
+	// Interval between checks of the config files for changes. Defaults
+	// to 5s.
+	Interval time.Duration
+
+	// ReloadOnSIGHUP also reloads the config when the process receives
+	// SIGHUP. Enable it in one watcher only, e.g. in main.
+	ReloadOnSIGHUP bool
+
+	// Logger for reloads and reload failures. Defaults to slog.Default().
+	Logger *slog.Logger
+}
+
+// Change describes a config reload. Fields are the TOML keys whose values
+// changed, e.g. "services.indexer.url".
+type Change[T any] struct {
+	Old, New *T
+	Fields   []string
+}
+
+// Watcher holds the current config, reloaded when the config files change,
+// or the process receives SIGHUP if WatchOptions.ReloadOnSIGHUP is set.
+//
+//	w, err := config.Watch(ctx, config.WatchOptions{Options: opts}, defaultConfig)
+//	if err != nil {
+//		return err
+//	}
+//	w.Subscribe(func(c config.Change[Config]) {
+//		if slices.Contains(c.Fields, "logger.level") {
+//			levelVar.Set(c.New.Logger.Level)
+//		}
+//	})
+//	cfg := w.Get()
+type Watcher[T any] struct {
+	opts     WatchOptions
+	defaults func() T
+
+	current atomic.Pointer[T]
+	sources atomic.Pointer[Sources]
+
+	mu          sync.Mutex // Serializes reloads and guards subscribers.
+	subscribers []func(Change[T])
+	files       map[string]fileStat
+}
+
+type fileStat struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch loads the config with Load and reloads it until ctx is done.
+// Each load starts from defaults(), or the zero value if defaults is nil,
+// so keys removed from the files revert to their defaults.
+//
+// A reload is validated like the initial load. On failure, the error is
+// logged and the current config is kept.
+func Watch[T any](ctx context.Context, opts WatchOptions, defaults func() T) (*Watcher[T], error) {
+	if defaults == nil {
+		defaults = func() T {
+			var zero T
+			return zero
+		}
+	}
+	opts.Interval = cmp.Or(opts.Interval, 5*time.Second)
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+
+	w := &Watcher[T]{
+		opts:     opts,
+		defaults: defaults,
+	}
+	w.files = w.stat()
+
+	cfg := defaults()
+	sources, err := Load(&cfg, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	w.current.Store(&cfg)
+	w.sources.Store(&sources)
+
+	go w.run(ctx)
+
+	return w, nil
+}
+
+// Get returns the current config. It must not be modified.
+func (w *Watcher[T]) Get() *T {
+	return w.current.Load()
+}
+
+// Sources returns the sources of the current config, see Load.
+func (w *Watcher[T]) Sources() Sources {
+	return *w.sources.Load()
+}
+
+// Subscribe registers fn to be called after each reload changing the
+// config. Subscribers are called in order, from the reloading goroutine,
+// and may call Subscribe or Reload.
+func (w *Watcher[T]) Subscribe(fn func(Change[T])) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, fn)
+}
+
+// Reload reloads the config and notifies subscribers if it changed. On
+// failure, the current config is kept.
+func (w *Watcher[T]) Reload() error {
+	change, ok, err := w.reload()
+	if err != nil || !ok {
+		return err
+	}
+
+	w.mu.Lock()
+	subscribers := slices.Clone(w.subscribers)
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(change)
+	}
+	return nil
+}
+
+// reload loads the config, and returns the change if the config changed.
+func (w *Watcher[T]) reload() (Change[T], bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.files = w.stat()
+
+	cfg := w.defaults()
+	sources, err := Load(&cfg, w.opts.Options)
+	if err != nil {
+		w.opts.Logger.Error("config: reload failed, keeping current config", slog.Any("error", err))
+		return Change[T]{}, false, err
+	}
+
+	old := w.current.Load()
+	fields := diff(reflect.ValueOf(old).Elem(), reflect.ValueOf(&cfg).Elem(), "")
+	w.sources.Store(&sources)
+	if len(fields) == 0 {
+		return Change[T]{}, false, nil
+	}
+	w.current.Store(&cfg)
+	w.opts.Logger.Info("config: reloaded", slog.Any("changed", fields))
+
+	return Change[T]{Old: old, New: &cfg, Fields: fields}, true, nil
+}
+
+func (w *Watcher[T]) run(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	if w.opts.ReloadOnSIGHUP {
+		signal.Notify(sighup, syscall.SIGHUP)
+		defer signal.Stop(sighup)
+	}
+
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			_ = w.Reload()
+		case <-ticker.C:
+			if w.changed() {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// stat returns the stats of the base and overlay config files. Missing
+// files are recorded with zero stats, so creating them triggers a reload.
+func (w *Watcher[T]) stat() map[string]fileStat {
+	files := map[string]fileStat{}
+	if w.opts.Path == "" {
+		return files
+	}
+	for _, path := range []string{w.opts.Path, OverlayPath(w.opts.Path, w.opts.Env)} {
+		var stat fileStat
+		if info, err := os.Stat(path); err == nil {
+			stat = fileStat{modTime: info.ModTime(), size: info.Size()}
+		}
+		files[path] = stat
+	}
+	return files
+}
+
+func (w *Watcher[T]) changed() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for path, stat := range w.stat() {
+		if w.files[path] != stat {
+			return true
+		}
+	}
+	return false
+}
+
+// diff returns the TOML keys of the fields that differ between a and b.
+func diff(a, b reflect.Value, path string) []string {
+	t := a.Type()
+
+	switch {
+	case t == reflect.TypeFor[Secret]():
+		if a.Interface().(Secret).Value() != b.Interface().(Secret).Value() { //nolint:forcetypeassert
+			return []string{path}
+		}
+		return nil
+
+	case t.Kind() == reflect.Pointer && !a.IsNil() && !b.IsNil():
+		return diff(a.Elem(), b.Elem(), path)
+
+	case t.Kind() == reflect.Struct && hasTOMLFields(t):
+		var fields []string
+		for i := range t.NumField() {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("toml"), ",")
+			if !field.IsExported() {
+				// Unexported fields with toml tags are decoded by custom
+				// unmarshalers, e.g. Service.url.
+				if name != "" && name != "-" && !unexportedEqual(a, b, i, name) {
+					fields = append(fields, joinKey(path, name))
+				}
+				continue
+			}
+			if key := tomlKey(field); key != "" {
+				fields = append(fields, diff(a.Field(i), b.Field(i), joinKey(path, key))...)
+			}
+		}
+		return fields
+
+	case t.Kind() == reflect.Map:
+		// Compare entries like fields, since values like Service hold
+		// pointers that differ on each load.
+		keys := map[string][2]reflect.Value{}
+		for _, m := range []reflect.Value{a, b} {
+			iter := m.MapRange()
+			for iter.Next() {
+				key := fmt.Sprint(iter.Key().Interface())
+				pair := keys[key]
+				if m == a {
+					pair[0] = iter.Value()
+				} else {
+					pair[1] = iter.Value()
+				}
+				keys[key] = pair
+			}
+		}
+		var fields []string
+		for _, key := range slices.Sorted(maps.Keys(keys)) {
+			pair := keys[key]
+			if !pair[0].IsValid() || !pair[1].IsValid() {
+				fields = append(fields, joinKey(path, key))
+				continue
+			}
+			fields = append(fields, diff(pair[0], pair[1], joinKey(path, key))...)
+		}
+		return fields
+
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		// Elements have no TOML keys, so any change reports the slice.
+		if a.Len() != b.Len() {
+			return []string{path}
+		}
+		for i := range a.Len() {
+			if len(diff(a.Index(i), b.Index(i), path)) > 0 {
+				return []string{path}
+			}
+		}
+		return nil
+	}
+
+	if a.CanInterface() && !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return []string{path}
+	}
+	return nil
+}
+
+// unexportedEqual reports whether the unexported field i of structs a and b
+// is equal. Fields of types with custom marshalers, e.g. Service.url, are
+// compared as marshaled, since their values hold pointers like
+// *url.Userinfo that differ on each load.
+func unexportedEqual(a, b reflect.Value, i int, name string) bool {
+	type marshaler interface{ fields(raw bool) []tomlField }
+	if a.CanInterface() {
+		if ma, ok := a.Interface().(marshaler); ok {
+			mb := b.Interface().(marshaler) //nolint:forcetypeassert
+			return reflect.DeepEqual(fieldValue(ma.fields(true), name), fieldValue(mb.fields(true), name))
+		}
+	}
+	return !a.Field(i).Comparable() || a.Field(i).Equal(b.Field(i))
+}
+
+func fieldValue(fields []tomlField, key string) any {
+	for _, field := range fields {
+		if field.key == key {
+			return field.value
+		}
+	}
+	return nil
+}
+
+// hasTOMLFields reports whether t has fields decoded from TOML, as opposed
+// to values decoded from text, like BaseURL.
+func hasTOMLFields(t reflect.Type) bool {
+	if isText(t) {
+		return false
+	}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if field.IsExported() || field.Tag.Get("toml") != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type AsyncPolicy string
+
+const (
+	// AsyncDrop drops records when the buffer is full. Logging never blocks.
+	AsyncDrop AsyncPolicy = "drop"
+	// AsyncBlock blocks the caller until there is space in the buffer.
+	AsyncBlock AsyncPolicy = "block"
+)
+
+const defaultAsyncBufferSize = 1024
+
+// AsyncConfig enables asynchronous logging, moving record encoding and
+// writing off the caller's goroutine.
+type AsyncConfig struct {
+	Enabled    bool        `toml:"enabled"`
+	BufferSize int         `toml:"buffer_size"` // Max number of buffered records. Defaults to 1024.
+	Policy     AsyncPolicy `toml:"policy"`      // "drop" (default) or "block".
+}
+
+// AsyncHandler is a slog.Handler that buffers records in a bounded queue and
+// passes them to the next handler from a background goroutine.
+//
+// Call Close on shutdown to flush buffered records. Records logged after
+// Close are passed to the next handler synchronously.
+type AsyncHandler struct {
+	*asyncQueue
+	handler slog.Handler
+}
+
+type asyncQueue struct {
+	records chan asyncRecord
+	policy  AsyncPolicy
+	next    slog.Handler
+	dropped atomic.Uint64
+	done    chan struct{}
+
+	closing   chan struct{} // Closed by Close, unblocks producers under AsyncBlock.
+	closeOnce sync.Once
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+type asyncRecord struct {
+	ctx     context.Context
+	handler slog.Handler
+	record  slog.Record
+	flushed chan struct{}
+}
+
+// NewAsyncHandler starts a background goroutine that passes buffered records
+// to the given handler.
+func NewAsyncHandler(handler slog.Handler, cfg AsyncConfig) *AsyncHandler {
+	q := &asyncQueue{
+		records: make(chan asyncRecord, cmp.Or(cfg.BufferSize, defaultAsyncBufferSize)),
+		policy:  cmp.Or(cfg.Policy, AsyncDrop),
+		next:    handler,
+		done:    make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	go q.run()
+
+	return &AsyncHandler{asyncQueue: q, handler: handler}
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+
+	for r := range q.records {
+		if r.flushed != nil {
+			close(r.flushed)
+			continue
+		}
+		_ = r.handler.Handle(r.ctx, r.record)
+	}
+}
+
+func (h *AsyncHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *AsyncHandler) Handle(ctx context.Context, record slog.Record) error {
+	r := asyncRecord{
+		ctx:     context.WithoutCancel(ctx),
+		handler: h.handler,
+		record:  record.Clone(),
+	}
+	if !h.enqueue(r) {
+		return h.handler.Handle(ctx, record) //nolint:wrapcheck
+	}
+	return nil
+}
+
+// enqueue buffers the record, or drops it if the buffer is full. It returns
+// false if the queue is closed, or is being closed while the caller is
+// blocked under AsyncBlock, so the caller writes the record itself.
+func (q *asyncQueue) enqueue(r asyncRecord) bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return false
+	}
+
+	if q.policy == AsyncBlock {
+		select {
+		case q.records <- r:
+			return true
+		case <-q.closing:
+			return false
+		}
+	}
+
+	select {
+	case q.records <- r:
+	default:
+		q.dropped.Add(1)
+	}
+	return true
+}
+
+func (h *AsyncHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &AsyncHandler{asyncQueue: h.asyncQueue, handler: h.handler.WithAttrs(attrs)}
+}
+
+func (h *AsyncHandler) WithGroup(name string) slog.Handler {
+	return &AsyncHandler{asyncQueue: h.asyncQueue, handler: h.handler.WithGroup(name)}
+}
+
+// Dropped returns the number of records dropped because the buffer was full.
+func (q *asyncQueue) Dropped() uint64 {
+	return q.dropped.Load()
+}
+
+// Flush waits until all records buffered before the call are written.
+func (q *asyncQueue) Flush(ctx context.Context) error {
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return nil
+	}
+	flushed := make(chan struct{})
+	select {
+	case q.records <- asyncRecord{flushed: flushed}:
+		q.mu.RUnlock()
+	case <-q.closing:
+		// Close flushes the buffer.
+		q.mu.RUnlock()
+		return nil
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return fmt.Errorf("flush async logger: %w", ctx.Err())
+	}
+
+	select {
+	case <-flushed:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush async logger: %w", ctx.Err())
+	}
+}
+
+// Close flushes all buffered records and stops the background goroutine.
+// It reports the number of dropped records, if any, to the next handler.
+//
+// Callers blocked on a full buffer under AsyncBlock write their records
+// synchronously, so they don't hold up Close past the ctx deadline.
+func (q *asyncQueue) Close(ctx context.Context) error {
+	q.closeOnce.Do(func() { close(q.closing) })
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.records)
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		return fmt.Errorf("close async logger: %w", ctx.Err())
+	}
+
+	if dropped := q.Dropped(); dropped > 0 {
+		record := slog.NewRecord(time.Now(), slog.LevelWarn, "logger: dropped records, async buffer was full", 0)
+		record.AddAttrs(slog.Uint64("dropped", dropped))
+		return q.next.Handle(ctx, record) //nolint:wrapcheck
+	}
+	return nil
+}
+
+var (
+	registeredMu sync.Mutex
+	registered   []*handlerResources
+)
+
+// Flush waits until records buffered by async loggers created by New are written.
+func Flush(ctx context.Context) error {
+	registeredMu.Lock()
+	resources := slices.Clone(registered)
+	registeredMu.Unlock()
+
+	var errs []error
+	for _, r := range resources {
+		errs = append(errs, r.flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// Dropped returns the number of records dropped by async loggers created by
+// New, because their buffer was full. Loggers released by Close are not
+// counted, Close reports their dropped records.
+func Dropped() uint64 {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	var dropped uint64
+	for _, r := range registered {
+		if r.async != nil {
+			dropped += r.async.Dropped()
+		}
+	}
+	return dropped
+}
+
+// Close flushes and stops async loggers created by New, and closes their
+// file and socket outputs. Call it before the program exits, so no logs
+// are lost:
+//
+//	defer logger.Close(context.Background())
+func Close(ctx context.Context) error {
+	registeredMu.Lock()
+	resources := registered
+	registered = nil
+	registeredMu.Unlock()
+
+	var errs []error
+	for _, r := range resources {
+		errs = append(errs, r.close(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+func register(r *handlerResources) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	registered = append(registered, r)
+}
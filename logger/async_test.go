@@ -0,0 +1,115 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/assert"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestAsyncHandler(t *testing.T) {
+	var out syncBuffer
+	h := NewAsyncHandler(slog.NewJSONHandler(&out, nil), AsyncConfig{Policy: AsyncBlock, BufferSize: 2})
+	logger := slog.New(h).With(slog.String("service", "api"))
+
+	for range 100 {
+		logger.Info("hello")
+	}
+
+	assert.NoError(t, h.Flush(context.Background()))
+	assert.Equal(t, 100, strings.Count(out.String(), `"service":"api"`))
+	assert.Zero(t, h.Dropped())
+
+	assert.NoError(t, h.Close(context.Background()))
+	logger.Info("after close")
+	assert.Contains(t, out.String(), "after close", "expected records after Close to be written synchronously")
+}
+
+func TestAsyncHandlerDrop(t *testing.T) {
+	blocked := make(chan struct{})
+	var out syncBuffer
+	h := NewAsyncHandler(&blockingHandler{Handler: slog.NewJSONHandler(&out, nil), unblock: blocked}, AsyncConfig{BufferSize: 1})
+	logger := slog.New(h)
+
+	for range 10 {
+		logger.Info("hello")
+	}
+	close(blocked)
+
+	assert.NoError(t, h.Close(context.Background()))
+	assert.NotZero(t, h.Dropped())
+	assert.Contains(t, out.String(), "dropped records")
+}
+
+func TestAsyncHandlerCloseBlocked(t *testing.T) {
+	blocked := make(chan struct{})
+	h := NewAsyncHandler(&blockingHandler{Handler: slog.NewJSONHandler(io.Discard, nil), unblock: blocked}, AsyncConfig{Policy: AsyncBlock, BufferSize: 1})
+	logger := slog.New(h)
+
+	logged := make(chan struct{})
+	go func() {
+		defer close(logged)
+		for range 3 {
+			logger.Info("hello")
+		}
+	}()
+	time.Sleep(50 * time.Millisecond) // Let the producer block on the full buffer.
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.Error(t, h.Close(ctx))
+	assert.True(t, time.Since(start) < time.Second, "expected Close to return at the ctx deadline")
+
+	close(blocked)
+	<-logged
+}
+
+func TestDropped(t *testing.T) {
+	blocked := make(chan struct{})
+	h := NewAsyncHandler(&blockingHandler{Handler: slog.NewJSONHandler(io.Discard, nil), unblock: blocked}, AsyncConfig{BufferSize: 1})
+	before := Dropped()
+	register(&handlerResources{async: h})
+
+	logger := slog.New(h)
+	for range 10 {
+		logger.Info("hello")
+	}
+	assert.True(t, Dropped()-before >= 8, "expected dropped records to be counted")
+
+	close(blocked)
+	assert.NoError(t, Close(context.Background()))
+}
+
+type blockingHandler struct {
+	slog.Handler
+	unblock chan struct{}
+}
+
+func (h *blockingHandler) Handle(ctx context.Context, record slog.Record) error {
+	<-h.unblock
+	return h.Handler.Handle(ctx, record)
+}
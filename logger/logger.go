@@ -2,13 +2,15 @@ package logger
 
 import (
 	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
-	"os"
 
-	"github.com/go-chi/httplog/v3"
 	"github.com/go-chi/traceid"
-	"github.com/golang-cz/devslog"
 
+	"github.com/0xsequence/go-libs/alert"
 	"github.com/0xsequence/go-libs/endpointlogger"
 	"github.com/0xsequence/go-libs/httpdebug"
 )
@@ -24,6 +26,17 @@ type Options struct {
 
 	// Use httpdebug header in logging
 	HTTPDebug *httpdebug.Header
+
+	// NoGlobals skips slog.SetDefault and slog.SetLogLoggerLevel in New,
+	// and registering the handler for the package-level Flush and Close.
+	// Useful in tests and in programs with multiple loggers.
+	NoGlobals bool
+
+	// AlertSinks are called for log records carrying an alert error (see
+	// alert.LogHandler), e.g. to report to Sentry or page on-call. The
+	// record passed to sinks is redacted if Config.Redact is enabled.
+	// The LevelAlert mapping for Config.Schema is installed regardless.
+	AlertSinks []func(ctx context.Context, record slog.Record, err error)
 }
 
 // Config can be used directly in toml config
@@ -31,6 +44,22 @@ type Config struct {
 	Level   slog.Level `toml:"level"`
 	Concise bool       `toml:"concise"`
 	Pretty  bool       `toml:"pretty"`
+
+	// Schema of the JSON log fields: "gcp" (default), "ecs", "otel",
+	// "datadog" or "plain".
+	Schema Schema `toml:"schema"`
+
+	// Outputs configures where logs are written. Defaults to a single
+	// stdout output using Level and Pretty.
+	Outputs []Output `toml:"outputs"`
+
+	// Async moves log encoding and writing to a background goroutine.
+	// Call logger.Close before exit to flush buffered records.
+	Async AsyncConfig `toml:"async"`
+
+	// Redact masks sensitive attrs and values, e.g. passwords, JWTs or
+	// emails. Disabled by default.
+	Redact RedactConfig `toml:"redact"`
 }
 
 var defaultOptions = &Options{
@@ -42,28 +71,120 @@ var defaultOptions = &Options{
 	Version:     "unknown",
 }
 
+// New creates a logger from Handler and sets it as the slog default logger,
+// unless Options.NoGlobals is set.
 func New(o *Options) *slog.Logger {
 	o = cmp.Or(o, defaultOptions)
 
-	handlerOptions := &slog.HandlerOptions{
-		AddSource:   true,
-		Level:       o.Config.Level,
-		ReplaceAttr: httplog.SchemaGCP.Concise(o.Config.Concise).ReplaceAttr,
+	logger := slog.New(Handler(o))
+
+	if o.NoGlobals {
+		return logger
 	}
 
-	var slogHandler slog.Handler
-	if o.Config.Pretty {
-		// Pretty logger for localhost development.
-		slogHandler = devslog.NewHandler(os.Stdout, &devslog.Options{
-			MaxSlicePrintSize: 20,
-			SortKeys:          true,
-			TimeFormat:        "[15:04:05.000]",
-			StringerFormatter: true,
-			HandlerOptions:    handlerOptions,
-		})
-	} else {
-		// JSON logger for production
-		slogHandler = slog.NewJSONHandler(os.Stdout, handlerOptions)
+	// set default log and slog logger, if somebody would use plain slog or log
+	// we would at least get proper JSON log format
+	slog.SetDefault(logger)
+
+	// set log error level for plain log, we shouldn't use log at all,
+	// but if that happen we can see the logs and errors and resolve it
+	// only place where we use it is in main for log.Fatalf()
+	slog.SetLogLoggerLevel(slog.LevelError)
+
+	return logger
+}
+
+// Handler builds the composed slog.Handler used by New without changing the
+// slog defaults, so callers can wrap it further:
+//
+//	handler := logger.Handler(opts)
+//	handler = alert.LogHandler(handler, alertFn)
+//	log := slog.New(handler)
+//
+// Its async buffer and file and socket outputs are released by Close,
+// unless Options.NoGlobals is set. Use HandlerWithClose to release them
+// without globals.
+func Handler(o *Options) slog.Handler {
+	o = cmp.Or(o, defaultOptions)
+	handler, resources := newHandler(o)
+	if !o.NoGlobals {
+		register(resources)
+	}
+	return handler
+}
+
+// HandlerWithClose is like Handler, but returns a func flushing the async
+// buffer and closing the file and socket outputs of this handler only,
+// instead of releasing them in Close.
+func HandlerWithClose(o *Options) (slog.Handler, func(ctx context.Context) error) {
+	handler, resources := newHandler(o)
+	return handler, resources.close
+}
+
+// handlerResources are the async handler and outputs opened by Handler.
+type handlerResources struct {
+	async   *AsyncHandler
+	closers []io.Closer
+}
+
+func (r *handlerResources) flush(ctx context.Context) error {
+	if r.async == nil {
+		return nil
+	}
+	return r.async.Flush(ctx)
+}
+
+func (r *handlerResources) close(ctx context.Context) error {
+	var errs []error
+	if r.async != nil {
+		errs = append(errs, r.async.Close(ctx))
+	}
+	for _, closer := range r.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func newHandler(o *Options) (slog.Handler, *handlerResources) {
+	o = cmp.Or(o, defaultOptions)
+	resources := &handlerResources{}
+
+	replaceAttr := o.Config.Schema.ReplaceAttr(o.Config.Concise)
+
+	outputs := o.Config.Outputs
+	if len(outputs) == 0 {
+		outputs = []Output{{Type: OutputStdout}}
+	}
+
+	var handlers []slog.Handler
+	var errs []error
+	for i, output := range outputs {
+		handler, closer, err := output.handler(o.Config, replaceAttr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("outputs[%d]: %w", i, err))
+			continue
+		}
+		if closer != nil {
+			resources.closers = append(resources.closers, closer)
+		}
+		if o.HTTPDebug != nil && o.HTTPDebug.Key != "" && o.HTTPDebug.Value != "" {
+			// Wrap each output, so debug mode bypasses per-output levels.
+			handler = httpdebug.LogHandler(*o.HTTPDebug)(handler)
+		}
+		handlers = append(handlers, handler)
+	}
+	if len(handlers) == 0 {
+		// Never lose logs because of misconfigured outputs.
+		handler, _, _ := Output{Type: OutputStderr}.handler(o.Config, replaceAttr)
+		handlers = append(handlers, handler)
+	}
+
+	slogHandler := newMultiHandler(handlers...)
+
+	if o.Config.Async.Enabled {
+		asyncHandler := NewAsyncHandler(slogHandler, o.Config.Async)
+		resources.async = asyncHandler
+		slogHandler = asyncHandler
 	}
 
 	// add traceid handler
@@ -72,28 +193,42 @@ func New(o *Options) *slog.Logger {
 	// add endpoint logger handler
 	slogHandler = endpointlogger.LogHandler(slogHandler)
 
-	if o.HTTPDebug != nil && o.HTTPDebug.Key != "" && o.HTTPDebug.Value != "" {
-		slogHandler = httpdebug.LogHandler(*o.HTTPDebug)(slogHandler)
+	// add redact handler
+	var redactor *redactor
+	if o.Config.Redact.Enabled {
+		var err error
+		redactor, err = newRedactor(o.Config.Redact)
+		if err != nil {
+			errs = append(errs, err)
+			redactor, _ = newRedactor(RedactConfig{})
+		}
+		slogHandler = &redactHandler{handler: slogHandler, redactor: redactor}
 	}
 
-	logger := slog.New(slogHandler)
+	// add alert handler
+	if len(o.AlertSinks) > 0 {
+		sinks := o.AlertSinks
+		slogHandler = alert.LogHandler(slogHandler, func(ctx context.Context, record slog.Record, err error) {
+			if redactor != nil {
+				record = redactor.redactRecord(record)
+			}
+			for _, sink := range sinks {
+				sink(ctx, record.Clone(), err)
+			}
+		})
+	}
 
 	// in JSON mode print service and version
 	if !o.Config.Pretty {
-		logger = logger.With(
+		slogHandler = slogHandler.WithAttrs([]slog.Attr{
 			slog.String("service", cmp.Or(o.ServiceName, "unknown")),
 			slog.String("version", cmp.Or(o.Version, "unknown")),
-		)
+		})
 	}
 
-	// set default log and slog logger, if somebody would use plain slog or log
-	// we would at least get proper JSON log format
-	slog.SetDefault(logger)
-
-	// set log error level for plain log, we shouldn't use log at all,
-	// but if that happen we can see the logs and errors and resolve it
-	// only place where we use it is in main for log.Fatalf()
-	slog.SetLogLoggerLevel(slog.LevelError)
+	for _, err := range errs {
+		slog.New(slogHandler).Error("logger: invalid config", slog.Any("error", err))
+	}
 
-	return logger
+	return slogHandler, resources
 }
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/test-go/testify/assert"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+func TestNewNoGlobals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	defaultLogger := slog.Default()
+	logger := New(&Options{
+		Config: Config{
+			Outputs: []Output{{Type: OutputFile, Path: path}},
+		},
+		ServiceName: "api",
+		Version:     "v1.0.0",
+		NoGlobals:   true,
+	})
+	assert.Equal(t, defaultLogger, slog.Default())
+
+	logger.Info("hello")
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "hello", record["message"])
+	assert.Equal(t, "INFO", record["severity"])
+	assert.Equal(t, "api", record["service"])
+	assert.Equal(t, "v1.0.0", record["version"])
+}
+
+func TestHandlerNoGlobalsNotRegistered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	registeredMu.Lock()
+	n := len(registered)
+	registeredMu.Unlock()
+
+	handler, closeFn := HandlerWithClose(&Options{
+		Config: Config{
+			Outputs: []Output{{Type: OutputFile, Path: path}},
+			Async:   AsyncConfig{Enabled: true},
+		},
+		NoGlobals: true,
+	})
+	logger := slog.New(handler)
+	Handler(&Options{Config: Config{Outputs: []Output{{Type: OutputFile, Path: path}}}, NoGlobals: true})
+
+	registeredMu.Lock()
+	assert.Len(t, registered, n)
+	registeredMu.Unlock()
+
+	// The package-level Close doesn't stop the handler or close its file.
+	assert.NoError(t, Close(context.Background()))
+	logger.Info("after global close")
+	assert.NoError(t, closeFn(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "after global close")
+}
+
+func TestNewAlertSinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	var alerts []slog.Record
+	logger := New(&Options{
+		Config: Config{
+			Outputs: []Output{{Type: OutputFile, Path: path}},
+			Redact:  RedactConfig{Enabled: true},
+		},
+		NoGlobals: true,
+		AlertSinks: []func(ctx context.Context, record slog.Record, err error){
+			func(ctx context.Context, record slog.Record, err error) {
+				alerts = append(alerts, record)
+			},
+		},
+	})
+
+	logger.Info("failed", slog.String("password", "hunter2"), slog.Any("error", alert.Errorf("timeout")))
+
+	assert.Len(t, alerts, 1)
+	alerts[0].Attrs(func(a slog.Attr) bool {
+		if a.Key == "password" {
+			assert.Equal(t, "[REDACTED]", a.Value.String())
+		}
+		return true
+	})
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal(data, &record))
+	assert.Equal(t, "ALERT", record["severity"])
+	assert.Equal(t, "[REDACTED]", record["password"])
+}
@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/golang-cz/devslog"
+)
+
+type OutputType string
+
+const (
+	OutputStdout OutputType = "stdout"
+	OutputStderr OutputType = "stderr"
+	OutputFile   OutputType = "file"
+	OutputSocket OutputType = "socket"
+)
+
+type Format string
+
+const (
+	FormatJSON   Format = "json"
+	FormatPretty Format = "pretty"
+)
+
+// Output configures a single log sink. Records are fanned out to all
+// configured outputs, each with its own format and level.
+//
+//	[[logger.outputs]]
+//	  type = "stdout"
+//
+//	[[logger.outputs]]
+//	  type = "file"
+//	  path = "/var/log/app/app.log"
+//	  level = "DEBUG"
+//	  max_size = 100
+//	  max_age = "24h"
+//	  max_backups = 7
+type Output struct {
+	Type   OutputType  `toml:"type"`   // Defaults to "stdout".
+	Format Format      `toml:"format"` // Defaults to "pretty" if Config.Pretty is set, "json" otherwise.
+	Level  *slog.Level `toml:"level"`  // Defaults to Config.Level.
+
+	// File output.
+	Path       string        `toml:"path"`        // Path of the active log file.
+	MaxSize    int           `toml:"max_size"`    // Rotate after the file reaches MaxSize megabytes. Zero disables size rotation.
+	MaxAge     time.Duration `toml:"max_age"`     // Rotate after the file is older than MaxAge. Zero disables age rotation.
+	MaxBackups int           `toml:"max_backups"` // Number of rotated files to keep. Zero keeps all of them.
+
+	// Socket output.
+	Network string `toml:"network"` // "unix" (default) or "unixgram", e.g. for syslog-style /dev/log.
+	Address string `toml:"address"` // Socket path.
+}
+
+func (o Output) writer() (io.Writer, error) {
+	switch o.Type {
+	case "", OutputStdout:
+		return os.Stdout, nil
+	case OutputStderr:
+		return os.Stderr, nil
+	case OutputFile:
+		if o.Path == "" {
+			return nil, fmt.Errorf("file output: path is required")
+		}
+		return newRotatingFile(o.Path, int64(o.MaxSize)*1024*1024, o.MaxAge, o.MaxBackups)
+	case OutputSocket:
+		if o.Address == "" {
+			return nil, fmt.Errorf("socket output: address is required")
+		}
+		return newSocketWriter(o.Network, o.Address)
+	default:
+		return nil, fmt.Errorf("unknown output type %q", o.Type)
+	}
+}
+
+// handler returns the output handler, and the writer to close for file and
+// socket outputs.
+func (o Output) handler(c Config, replaceAttr func(groups []string, a slog.Attr) slog.Attr) (slog.Handler, io.Closer, error) {
+	w, err := o.writer()
+	if err != nil {
+		return nil, nil, err
+	}
+	var closer io.Closer
+	if o.Type == OutputFile || o.Type == OutputSocket {
+		closer, _ = w.(io.Closer)
+	}
+
+	level := c.Level
+	if o.Level != nil {
+		level = *o.Level
+	}
+
+	handlerOptions := &slog.HandlerOptions{
+		AddSource:   true,
+		Level:       level,
+		ReplaceAttr: replaceAttr,
+	}
+
+	format := o.Format
+	if format == "" {
+		format = FormatJSON
+		if c.Pretty {
+			format = FormatPretty
+		}
+	}
+
+	switch format {
+	case FormatPretty:
+		// Pretty logger for localhost development.
+		return devslog.NewHandler(w, &devslog.Options{
+			MaxSlicePrintSize: 20,
+			SortKeys:          true,
+			TimeFormat:        "[15:04:05.000]",
+			StringerFormatter: true,
+			HandlerOptions:    handlerOptions,
+		}), closer, nil
+	case FormatJSON:
+		// JSON logger for production
+		return slog.NewJSONHandler(w, handlerOptions), closer, nil
+	default:
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
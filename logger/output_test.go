@@ -0,0 +1,122 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/assert"
+)
+
+func TestMultipleOutputs(t *testing.T) {
+	dir := t.TempDir()
+	jsonPath := filepath.Join(dir, "app.json")
+	prettyPath := filepath.Join(dir, "app.pretty")
+	warn := slog.LevelWarn
+
+	handler, closeFn := HandlerWithClose(&Options{
+		Config: Config{
+			Level: slog.LevelDebug,
+			Outputs: []Output{
+				{Type: OutputFile, Path: jsonPath},
+				{Type: OutputFile, Path: prettyPath, Format: FormatPretty, Level: &warn},
+			},
+		},
+	})
+	logger := slog.New(handler)
+	logger.Debug("debug message")
+	logger.Warn("warn message")
+	assert.NoError(t, closeFn(context.Background()))
+
+	data, err := os.ReadFile(jsonPath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if assert.Len(t, lines, 2) {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+		assert.Equal(t, "debug message", record["message"])
+	}
+
+	data, err = os.ReadFile(prettyPath)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "debug message")
+	assert.Contains(t, string(data), "warn message")
+	assert.False(t, json.Valid(data), "expected pretty output")
+}
+
+func TestSocketOutput(t *testing.T) {
+	// Socket paths are limited to ~100 bytes, t.TempDir() may be too long.
+	dir, err := os.MkdirTemp("", "log")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	address := filepath.Join(dir, "log.sock")
+
+	ln, err := net.Listen("unix", address)
+	assert.NoError(t, err)
+	defer ln.Close()
+
+	lines := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		lines <- line
+	}()
+
+	handler, closeFn := HandlerWithClose(&Options{
+		Config: Config{Outputs: []Output{{Type: OutputSocket, Address: address}}},
+	})
+	slog.New(handler).Info("hello socket")
+
+	var record map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(<-lines), &record))
+	assert.Equal(t, "hello socket", record["message"])
+	assert.NoError(t, closeFn(context.Background()))
+
+	_, err = newSocketWriter("tcp", address)
+	assert.Error(t, err)
+}
+
+func TestStderrFallback(t *testing.T) {
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+	stderr := os.Stderr
+	os.Stderr = w
+	defer func() { os.Stderr = stderr }()
+
+	logger := New(&Options{
+		Config:    Config{Outputs: []Output{{Type: OutputFile}}},
+		NoGlobals: true,
+	})
+	logger.Info("still logged")
+	w.Close()
+
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "logger: invalid config")
+	assert.Contains(t, string(data), "outputs[0]: file output: path is required")
+	assert.Contains(t, string(data), "still logged")
+}
+
+func TestCloseOutputs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	handler, closeFn := HandlerWithClose(&Options{
+		Config: Config{Outputs: []Output{{Type: OutputFile, Path: path}}},
+	})
+	slog.New(handler).Info("before close")
+	assert.NoError(t, closeFn(context.Background()))
+
+	// Writes after Close fail, since the file is closed.
+	assert.Error(t, handler.Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelInfo, "after close", 0)))
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/0xsequence/go-libs/config"
+	"github.com/0xsequence/go-libs/xlog"
+)
+
+// RedactConfig configures masking of sensitive data in logs. Redaction is
+// opt-in, since it matches regexps against every message and string attr.
+//
+// Attrs whose key contains one of the key patterns (case-insensitive,
+// ignoring "_", "-" and ".") are replaced entirely. The default key
+// patterns are config.SensitiveKeys, shared with config.Dump. Parts of string values,
+// error messages and log messages matching one of the value patterns are
+// replaced. Errors are wrapped, so errors.As still finds alert errors.
+type RedactConfig struct {
+	Enabled bool     `toml:"enabled"`
+	Keys    []string `toml:"keys"`   // Additional key patterns.
+	Values  []string `toml:"values"` // Additional value regexps.
+}
+
+var defaultRedactValues = []string{
+	// JWT
+	`eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	// Bearer token
+	`(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`,
+	// Private key hex. 0x-prefixed values are skipped, since these are
+	// mostly tx hashes.
+	`\b[0-9a-fA-F]{64}\b`,
+	// Email
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+}
+
+// RedactHandler returns a slog.Handler that masks sensitive attrs and values
+// before passing records to the next handler. cfg.Enabled is ignored.
+func RedactHandler(handler slog.Handler, cfg RedactConfig) (slog.Handler, error) {
+	r, err := newRedactor(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &redactHandler{handler: handler, redactor: r}, nil
+}
+
+func newRedactor(cfg RedactConfig) (*redactor, error) {
+	r := &redactor{}
+	for _, key := range cfg.Keys {
+		r.keys = append(r.keys, normalizeKey(key))
+	}
+
+	patterns := slices.Concat(defaultRedactValues, cfg.Values)
+	for _, pattern := range cfg.Values {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return nil, fmt.Errorf("redact value %q: %w", pattern, err)
+		}
+	}
+	r.values = regexp.MustCompile("(?:" + strings.Join(patterns, ")|(?:") + ")")
+
+	return r, nil
+}
+
+type redactor struct {
+	keys   []string
+	values *regexp.Regexp
+}
+
+func normalizeKey(key string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+}
+
+func (r *redactor) isSensitiveKey(key string) bool {
+	if config.IsSensitiveKey(key) {
+		return true
+	}
+	key = normalizeKey(key)
+	for _, pattern := range r.keys {
+		if strings.Contains(key, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) redactString(s string) string {
+	return r.values.ReplaceAllLiteralString(s, xlog.Redacted)
+}
+
+func (r *redactor) redactAttr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	if r.isSensitiveKey(a.Key) {
+		return slog.String(a.Key, xlog.Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(r.redactString(a.Value.String()))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			if msg := r.redactString(err.Error()); msg != err.Error() {
+				a.Value = slog.AnyValue(&redactedError{msg: msg, err: err})
+			}
+		}
+	case slog.KindGroup:
+		a.Value = slog.GroupValue(r.redactAttrs(a.Value.Group())...)
+	}
+	return a
+}
+
+func (r *redactor) redactAttrs(attrs []slog.Attr) []slog.Attr {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = r.redactAttr(a)
+	}
+	return redacted
+}
+
+func (r *redactor) redactRecord(record slog.Record) slog.Record {
+	redacted := slog.NewRecord(record.Time, record.Level, r.redactString(record.Message), record.PC)
+	record.Attrs(func(a slog.Attr) bool {
+		redacted.AddAttrs(r.redactAttr(a))
+		return true
+	})
+	return redacted
+}
+
+// redactedError masks the message of err, but keeps it in the chain.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
+
+type redactHandler struct {
+	handler  slog.Handler
+	redactor *redactor
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, record slog.Record) error {
+	return h.handler.Handle(ctx, h.redactor.redactRecord(record)) //nolint:wrapcheck
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &redactHandler{handler: h.handler.WithAttrs(h.redactor.redactAttrs(attrs)), redactor: h.redactor}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{handler: h.handler.WithGroup(name), redactor: h.redactor}
+}
@@ -0,0 +1,213 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is an io.Writer that appends to a file and rotates it
+// once it grows over maxSize bytes or gets older than maxAge.
+// Rotated files are renamed to "<name>-<timestamp><ext>" next to the
+// active file and only the newest maxBackups of them are kept.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu       sync.Mutex
+	file     *os.File // Nil after a failed rotation.
+	size     int64
+	openedAt time.Time
+	closed   bool
+
+	rename func(oldpath, newpath string) error // os.Rename, replaced in tests.
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log dir: %w", err)
+	}
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		rename:     os.Rename,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	var rotateErr error
+	if f.shouldRotate(int64(len(p))) {
+		rotateErr = f.rotate()
+	}
+	if f.file == nil {
+		// Reopen after a failed rotation.
+		if err := f.open(); err != nil {
+			return 0, errors.Join(rotateErr, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, errors.Join(rotateErr, err)
+}
+
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	return f.file.Close() //nolint:wrapcheck
+}
+
+func (f *rotatingFile) shouldRotate(n int64) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+n > f.maxSize {
+		return true
+	}
+	if f.maxAge > 0 && time.Since(f.openedAt) > f.maxAge {
+		return true
+	}
+	return false
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	if f.size > 0 {
+		// The active file was created by the last rotation. Without backups,
+		// its age is unknown, so it's rotated on the first write if maxAge
+		// is set. ModTime is the last write, not the creation.
+		f.openedAt = f.lastRotation()
+	}
+	return nil
+}
+
+// lastRotation returns the timestamp of the newest backup, or the zero time.
+func (f *rotatingFile) lastRotation() time.Time {
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	var last time.Time
+	for _, backup := range f.backups(prefix, ext) {
+		if t, ok := backupTime(strings.TrimSuffix(strings.TrimPrefix(backup, prefix), ext)); ok && t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// rotate renames the active file to a backup and opens a new one. If it
+// fails, f.file is nil and Write reopens the active file.
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+	if err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+
+	ext := filepath.Ext(f.path)
+	prefix := strings.TrimSuffix(f.path, ext) + "-"
+	timestamp := time.Now().UTC().Format(backupTimeFormat)
+	backup := prefix + timestamp + ext
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s%s.%d%s", prefix, timestamp, i, ext)
+	}
+	if err := f.rename(f.path, backup); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	if f.maxBackups > 0 {
+		backups := f.backups(prefix, ext)
+		// Timestamps sort lexicographically, oldest first.
+		slices.Sort(backups)
+		for len(backups) > f.maxBackups {
+			os.Remove(backups[0])
+			backups = backups[1:]
+		}
+	}
+
+	return nil
+}
+
+// backups returns the rotated files of this writer, i.e. files named
+// "<prefix><timestamp>[.N]<ext>", so unrelated files like "app-old.log"
+// are never removed.
+func (f *rotatingFile) backups(prefix, ext string) []string {
+	matches, _ := filepath.Glob(prefix + "*" + ext)
+	var backups []string
+	for _, match := range matches {
+		name := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if isBackupName(name) {
+			backups = append(backups, match)
+		}
+	}
+	return backups
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isBackupName reports whether name is "<timestamp>" or "<timestamp>.N".
+func isBackupName(name string) bool {
+	_, ok := backupTime(name)
+	return ok
+}
+
+// backupTime returns the timestamp of a backup name, see isBackupName.
+func backupTime(name string) (time.Time, bool) {
+	if t, err := time.Parse(backupTimeFormat, name); err == nil {
+		return t, true
+	}
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return time.Time{}, false
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(backupTimeFormat, name[:i])
+	return t, err == nil
+}
@@ -0,0 +1,96 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/test-go/testify/assert"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	unrelated := filepath.Join(dir, "app-old.log")
+	assert.NoError(t, os.WriteFile(unrelated, []byte("keep"), 0o644))
+
+	f, err := newRotatingFile(path, 10, 0, 2)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	for _, line := range []string{"line-001\n", "line-002\n", "line-003\n", "line-004\n"} {
+		_, err := f.Write([]byte(line))
+		assert.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line-004\n", string(data))
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log"))
+	assert.NoError(t, err)
+	assert.Len(t, backups, 3, "expected old backups to be removed")
+	_, err = os.Stat(unrelated)
+	assert.NoError(t, err, "expected unrelated files to be kept")
+}
+
+func TestIsBackupName(t *testing.T) {
+	assert.True(t, isBackupName("2024-01-02T15-04-05.000"))
+	assert.True(t, isBackupName("2024-01-02T15-04-05.000.3"))
+	assert.False(t, isBackupName("old"))
+	assert.False(t, isBackupName("2024-01-02"))
+	assert.False(t, isBackupName("2024-01-02T15-04-05.000.x"))
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	f, err := newRotatingFile(path, 10, 0, 0)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("line-001\n"))
+	assert.NoError(t, err)
+
+	f.rename = func(oldpath, newpath string) error {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: os.ErrPermission}
+	}
+	_, err = f.Write([]byte("line-002\n"))
+	assert.Error(t, err)
+	f.rename = os.Rename
+
+	// The active file is reopened, so no logs are lost, and the next
+	// write rotates it.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line-001\nline-002\n", string(data))
+
+	_, err = f.Write([]byte("line-003\n"))
+	assert.NoError(t, err)
+	data, err = os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "line-003\n", string(data))
+}
+
+func TestRotatingFileAgeAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte("old\n"), 0o644))
+
+	// The last rotation was 2h ago, although the file was written just now.
+	backup := filepath.Join(dir, "app-"+time.Now().Add(-2*time.Hour).UTC().Format(backupTimeFormat)+".log")
+	assert.NoError(t, os.WriteFile(backup, nil, 0o644))
+
+	f, err := newRotatingFile(path, 0, time.Hour, 0)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("new\n"))
+	assert.NoError(t, err)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new\n", string(data), "expected file older than max age to be rotated")
+}
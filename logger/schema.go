@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/go-chi/httplog/v3"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+// Schema selects the log field names and values expected by the log
+// platform the service is deployed to.
+type Schema string
+
+const (
+	SchemaGCP     Schema = "gcp"     // Google Cloud Logging (default).
+	SchemaECS     Schema = "ecs"     // Elastic Common Schema.
+	SchemaOTEL    Schema = "otel"    // OpenTelemetry log data model.
+	SchemaDatadog Schema = "datadog" // Datadog standard attributes.
+	SchemaPlain   Schema = "plain"   // Plain log/slog field names.
+)
+
+// httplogSchemaDatadog maps log fields to Datadog standard attributes.
+//
+// Reference: https://docs.datadoghq.com/standard-attributes
+var httplogSchemaDatadog = &httplog.Schema{
+	Timestamp:          "timestamp",
+	Level:              "status",
+	Message:            "message",
+	ErrorMessage:       "error.message",
+	ErrorType:          "error.kind",
+	ErrorStackTrace:    "error.stack",
+	SourceFile:         "logger.file_name",
+	SourceLine:         "logger.line",
+	SourceFunction:     "logger.method_name",
+	RequestURL:         "http.url",
+	RequestMethod:      "http.method",
+	RequestPath:        "http.url_details.path",
+	RequestRemoteIP:    "network.client.ip",
+	RequestHost:        "http.url_details.host",
+	RequestScheme:      "http.url_details.scheme",
+	RequestProto:       "http.version",
+	RequestHeaders:     "http.request.headers",
+	RequestBody:        "http.request.body",
+	RequestBytes:       "network.bytes_read",
+	RequestBytesUnread: "http.request.unread_bytes",
+	RequestUserAgent:   "http.useragent",
+	RequestReferer:     "http.referer",
+	ResponseHeaders:    "http.response.headers",
+	ResponseBody:       "http.response.body",
+	ResponseStatus:     "http.status_code",
+	ResponseDuration:   "duration",
+	ResponseBytes:      "network.bytes_written",
+}
+
+func (s *Schema) UnmarshalText(text []byte) error {
+	switch schema := Schema(text); schema {
+	case "", SchemaGCP, SchemaECS, SchemaOTEL, SchemaDatadog, SchemaPlain:
+		*s = schema
+		return nil
+	}
+	return fmt.Errorf("unknown log schema %q, supported=(gcp,ecs,otel,datadog,plain)", text)
+}
+
+// HTTPLogSchema returns the httplog schema, e.g. for httplog.RequestLogger.
+// It returns nil for SchemaPlain.
+func (s Schema) HTTPLogSchema() *httplog.Schema {
+	switch s {
+	case "", SchemaGCP:
+		return httplog.SchemaGCP
+	case SchemaECS:
+		return httplog.SchemaECS
+	case SchemaOTEL:
+		return httplog.SchemaOTEL
+	case SchemaDatadog:
+		return httplogSchemaDatadog
+	default:
+		return nil
+	}
+}
+
+// AlertAttr returns the level attr that represents alert.LevelAlert in the schema.
+func (s Schema) AlertAttr(concise bool) slog.Attr {
+	key := slog.LevelKey
+	if schema := s.HTTPLogSchema(); schema != nil && schema.Concise(concise).Level != "" {
+		key = schema.Concise(concise).Level
+	}
+	// Uppercase like the other levels, e.g. "INFO", in every schema.
+	return slog.String(key, "ALERT")
+}
+
+// ReplaceAttr returns slog.HandlerOptions.ReplaceAttr renaming fields to the
+// schema and mapping alert.LevelAlert to the schema's alert severity.
+func (s Schema) ReplaceAttr(concise bool) func(groups []string, a slog.Attr) slog.Attr {
+	var next func(groups []string, a slog.Attr) slog.Attr
+	if schema := s.HTTPLogSchema(); schema != nil {
+		next = schema.Concise(concise).ReplaceAttr
+	}
+	return alert.ReplaceAttr(next, s.AlertAttr(concise))
+}
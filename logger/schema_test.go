@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/httplog/v3"
+	"github.com/test-go/testify/assert"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+func TestSchemaAlertLevel(t *testing.T) {
+	tt := []struct {
+		schema  Schema
+		concise bool
+		key     string
+		value   string
+	}{
+		{schema: "", key: "severity", value: "ALERT"},
+		{schema: SchemaGCP, key: "severity", value: "ALERT"},
+		{schema: SchemaGCP, concise: true, key: "level", value: "ALERT"},
+		{schema: SchemaECS, key: "log.level", value: "ALERT"},
+		{schema: SchemaOTEL, key: "severity_text", value: "ALERT"},
+		{schema: SchemaDatadog, key: "status", value: "ALERT"},
+		{schema: SchemaPlain, key: "level", value: "ALERT"},
+	}
+
+	for _, tt := range tt {
+		t.Run(string(tt.schema), func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+				Level:       alert.LevelAlert,
+				ReplaceAttr: tt.schema.ReplaceAttr(tt.concise),
+			}))
+			logger.Log(context.Background(), alert.LevelAlert, "alert")
+
+			var got map[string]any
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+			assert.Equal(t, tt.value, got[tt.key])
+		})
+	}
+}
+
+func TestSchemaUnmarshalText(t *testing.T) {
+	var s Schema
+	assert.NoError(t, s.UnmarshalText([]byte("ecs")))
+	assert.Equal(t, SchemaECS, s)
+	assert.Error(t, s.UnmarshalText([]byte("splunk")))
+}
+
+func TestSchemaDetectPanics(t *testing.T) {
+	for _, schema := range []Schema{SchemaGCP, SchemaECS, SchemaOTEL, SchemaDatadog} {
+		t.Run(string(schema), func(t *testing.T) {
+			var gotErr error
+			handler := alert.LogHandler(slog.NewJSONHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+				gotErr = err
+			}, alert.DetectPanics())
+
+			middleware := httplog.RequestLogger(slog.New(handler), &httplog.Options{
+				Schema:        schema.HTTPLogSchema(),
+				RecoverPanics: true,
+			})
+			h := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				panic("nil map")
+			}))
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+			if assert.Error(t, gotErr, "expected panic alert") {
+				assert.Equal(t, "panic: nil map", gotErr.Error())
+				assert.Equal(t, []string{"panic"}, alert.TagsOf(gotErr))
+			}
+		})
+	}
+}
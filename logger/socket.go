@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// socketWriter writes each log record to a local Unix socket. The connection
+// is re-established lazily after a failed write, so a restarted log collector
+// doesn't require restarting the service.
+type socketWriter struct {
+	network string
+	address string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func newSocketWriter(network, address string) (*socketWriter, error) {
+	if network == "" {
+		network = "unix"
+	}
+	if network != "unix" && network != "unixgram" {
+		return nil, fmt.Errorf("socket output: unsupported network %q", network)
+	}
+	w := &socketWriter{network: network, address: address}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *socketWriter) dial() error {
+	conn, err := net.Dial(w.network, w.address)
+	if err != nil {
+		return fmt.Errorf("dial log socket: %w", err)
+	}
+	w.conn = conn
+	return nil
+}
+
+func (w *socketWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		if err := w.dial(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.conn.Write(p)
+	if err != nil {
+		w.conn.Close()
+		w.conn = nil
+		return n, fmt.Errorf("write log socket: %w", err)
+	}
+	return n, nil
+}
+
+func (w *socketWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err //nolint:wrapcheck
+}
@@ -1,25 +1,36 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
 
-	"github.com/go-chi/chi/v5/middleware"
-
 	"github.com/0xsequence/go-libs/config"
 )
 
 // BasicAuth protects routes with basic authentication. Returns 404 if credentials are not configured.
+// The password is read on each request, so a rotated secret (see config.Secret.Reload) takes effect
+// immediately.
 func BasicAuth(creds config.BasicAuth) func(next http.Handler) http.Handler {
-	if creds.Username == "" || creds.Password == "" {
-		return func(next http.Handler) http.Handler {
-			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			password := creds.Password.Value()
+			if creds.Username == "" || password == "" {
 				// Missing or misconfigured credentials.
 				// Return HTTP 404.
 				http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-			})
-		}
+				return
+			}
+
+			user, pass, ok := r.BasicAuth()
+			if !ok ||
+				subtle.ConstantTimeCompare([]byte(user), []byte(creds.Username)) != 1 ||
+				subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+				w.Header().Add("WWW-Authenticate", `Basic realm="sequence"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
 	}
-	return middleware.BasicAuth("sequence", map[string]string{
-		creds.Username: creds.Password,
-	})
 }
@@ -3,6 +3,8 @@ package middleware
 import (
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
@@ -15,7 +17,7 @@ func TestBasicAuth(t *testing.T) {
 	t.Run("valid creds", func(t *testing.T) {
 		creds := config.BasicAuth{
 			Username: "testuser",
-			Password: "testpass",
+			Password: config.LiteralSecret("testpass"),
 		}
 
 		r := chi.NewRouter()
@@ -54,10 +56,31 @@ func TestBasicAuth(t *testing.T) {
 		})
 	})
 
+	t.Run("rotated password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "password")
+		assert.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+		password, err := config.NewSecret("file:" + path)
+		assert.NoError(t, err)
+
+		r := chi.NewRouter()
+		r.Use(BasicAuth(config.BasicAuth{Username: "testuser", Password: password}))
+		r.Get("/protected", func(w http.ResponseWriter, r *http.Request) {})
+
+		assert.NoError(t, os.WriteFile(path, []byte("new"), 0o600))
+		assert.NoError(t, password.Reload())
+
+		for pass, want := range map[string]int{"old": http.StatusUnauthorized, "new": http.StatusOK} {
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.SetBasicAuth("testuser", pass)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+			assert.Equal(t, want, rr.Code, pass)
+		}
+	})
+
 	t.Run("missing creds", func(t *testing.T) {
 		creds := config.BasicAuth{
 			Username: "",
-			Password: "",
 		}
 
 		r := chi.NewRouter()
@@ -80,7 +103,6 @@ func TestBasicAuth(t *testing.T) {
 	t.Run("with partial credentials configured", func(t *testing.T) {
 		creds := config.BasicAuth{
 			Username: "testuser",
-			Password: "",
 		}
 
 		r := chi.NewRouter()
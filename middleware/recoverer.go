@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/httplog/v3"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+type RecovererOpts struct {
+	// Recover http.ErrAbortHandler, e.g. of a ReverseProxy on client
+	// disconnects, and return without responding. By default, it is
+	// re-panicked, so net/http aborts the response without logging. It is
+	// never alerted.
+	RecoverAbort bool
+
+	// Logger logs recovered panics, for use without httplog.RequestLogger.
+	// Its handler chain should include alert.LogHandler. By default, the
+	// panic is set on the request log entry of httplog.RequestLogger, and
+	// isn't logged at all without it.
+	Logger *slog.Logger
+}
+
+// Recoverer recovers from panics in the underlying handlers, converts them
+// to alert errors with the panic stack trace and responds with a webrpc
+// WebrpcServerPanic error (HTTP 500). The panic value is only logged and
+// alerted, never sent to the client.
+//
+// The alert error is set on the request log entry via httplog.SetError, so
+// Recoverer must be used after httplog.RequestLogger, whose handler chain
+// should include alert.LogHandler:
+//
+//	r.Use(httplog.RequestLogger(logger, opts))
+//	r.Use(middleware.Recoverer(middleware.RecovererOpts{}))
+//
+// Without httplog.RequestLogger, set RecovererOpts.Logger:
+//
+//	r.Use(middleware.Recoverer(middleware.RecovererOpts{Logger: logger}))
+func Recoverer(opts RecovererOpts) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler { //nolint:errorlint
+					if opts.RecoverAbort {
+						return
+					}
+					panic(rec)
+				}
+
+				// Skip ErrorSkip, this func and runtime.gopanic, so the stack trace
+				// starts at the panicking function.
+				err := alert.ErrorSkip(3, panicError(rec), alert.WithSeverity(alert.SeverityCritical), alert.Tags("panic"))
+				if opts.Logger != nil {
+					opts.Logger.ErrorContext(r.Context(), "panic recovered",
+						slog.String("method", r.Method),
+						slog.String("path", r.URL.Path),
+						slog.Any("error", err),
+					)
+				} else {
+					httplog.SetError(r.Context(), err)
+				}
+
+				if r.Header.Get("Connection") == "Upgrade" {
+					return
+				}
+				respondPanic(w)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func panicError(rec any) error {
+	if err, ok := rec.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", rec)
+}
+
+// webrpcError mirrors the error response of webrpc-generated servers.
+type webrpcError struct {
+	Error  string `json:"error"`
+	Code   int    `json:"code"`
+	Msg    string `json:"msg"`
+	Cause  string `json:"cause,omitempty"`
+	Status int    `json:"status"`
+}
+
+func respondPanic(w http.ResponseWriter) {
+	body, _ := json.Marshal(webrpcError{
+		Error:  "WebrpcServerPanic",
+		Code:   -6,
+		Msg:    "server panic",
+		Cause:  "internal server error",
+		Status: http.StatusInternalServerError,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	w.Write(body)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/httplog/v3"
+	"github.com/test-go/testify/assert"
+
+	"github.com/0xsequence/go-libs/alert"
+)
+
+func TestRecoverer(t *testing.T) {
+	var alertErr error
+	handler := alert.LogHandler(slog.NewJSONHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+		alertErr = err
+	})
+
+	r := chi.NewRouter()
+	r.Use(httplog.RequestLogger(slog.New(handler), &httplog.Options{}))
+	r.Use(Recoverer(RecovererOpts{}))
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+
+	var body map[string]any
+	assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	assert.Equal(t, map[string]any{
+		"error":  "WebrpcServerPanic",
+		"code":   float64(-6),
+		"msg":    "server panic",
+		"cause":  "internal server error",
+		"status": float64(500),
+	}, body)
+
+	if assert.Error(t, alertErr) {
+		assert.Equal(t, "panic: boom", alertErr.Error())
+		assert.Equal(t, alert.SeverityCritical, alert.SeverityOf(alertErr))
+		assert.Equal(t, []string{"panic"}, alert.TagsOf(alertErr))
+
+		stack := alert.StackTrace(alertErr)
+		if assert.NotEmpty(t, stack) {
+			assert.True(t, strings.HasPrefix(stack[0].Function, "github.com/0xsequence/go-libs/middleware.TestRecoverer"), stack[0].Function)
+		}
+	}
+}
+
+func TestRecovererLogger(t *testing.T) {
+	var alertErr error
+	var record slog.Record
+	handler := alert.LogHandler(slog.NewJSONHandler(io.Discard, nil), func(ctx context.Context, r slog.Record, err error) {
+		alertErr, record = err, r
+	})
+
+	// No httplog.RequestLogger.
+	r := chi.NewRouter()
+	r.Use(Recoverer(RecovererOpts{Logger: slog.New(handler)}))
+	r.Get("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rr.Code)
+	if assert.Error(t, alertErr) {
+		assert.Equal(t, "panic: boom", alertErr.Error())
+		assert.Equal(t, "panic recovered", record.Message)
+	}
+}
+
+func TestRecovererAbortHandler(t *testing.T) {
+	panicHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+
+	var alertErr error
+	handler := alert.LogHandler(slog.NewJSONHandler(io.Discard, nil), func(ctx context.Context, record slog.Record, err error) {
+		alertErr = err
+	})
+	serve := func(opts RecovererOpts, rr *httptest.ResponseRecorder) {
+		httplog.RequestLogger(slog.New(handler), &httplog.Options{})(Recoverer(opts)(panicHandler)).
+			ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	t.Run("re-panics by default", func(t *testing.T) {
+		assert.Panics(t, func() {
+			serve(RecovererOpts{}, httptest.NewRecorder())
+		})
+	})
+
+	t.Run("recovered with RecoverAbort", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		serve(RecovererOpts{RecoverAbort: true}, rr)
+		assert.Empty(t, rr.Body.String())
+	})
+
+	assert.Nil(t, alertErr, "aborts are never alerted")
+}
@@ -0,0 +1,35 @@
+package xlog
+
+import (
+	"log/slog"
+)
+
+// Redacted replaces sensitive values in logs.
+const Redacted = "[REDACTED]"
+
+// Secret is a string that always renders as "[REDACTED]" in logs, fmt and
+// text/JSON encoding. Use string(secret) to access the value.
+//
+//	logger.Info("signing request", slog.Any("jwt", xlog.Secret(token)))
+type Secret string
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(Redacted)
+}
+
+func (s Secret) String() string {
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return Redacted
+}
+
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// slog.Any(key, xlog.Secret(value))
+func SecretString(key string, value string) slog.Attr {
+	return slog.Any(key, Secret(value))
+}
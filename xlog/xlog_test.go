@@ -214,3 +214,14 @@ func TestPointerSlice(t *testing.T) {
 	assert.Equal(t, "sampleSlice", attr.Key)
 	assert.Equal(t, expected, attr.Value.String())
 }
+
+func TestSecret(t *testing.T) {
+	secret := xlog.Secret("password")
+
+	assert.Equal(t, "[REDACTED]", secret.String())
+	assert.Equal(t, "[REDACTED] [REDACTED] [REDACTED]", fmt.Sprintf("%v %+v %#v", secret, secret, secret))
+	assert.Equal(t, "password", string(secret))
+
+	attr := xlog.SecretString("password", "password")
+	assert.Equal(t, "[REDACTED]", attr.Value.Resolve().String())
+}